@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReconcileMode selects how the controller writes the children of a App.
+type ReconcileMode string
+
+const (
+	// ReconcileModeUpdate creates missing children and overwrites drifted
+	// ones with full-object updates.
+	ReconcileModeUpdate ReconcileMode = "update"
+	// ReconcileModeApply writes children with server-side apply, so the
+	// controller only owns the fields it renders and other field managers
+	// (HPA, service meshes injecting annotations, ...) can own the rest.
+	ReconcileModeApply ReconcileMode = "apply"
+)
+
+// fieldManager is the field manager name used for server-side apply.
+const fieldManager = controllerAgentName
+
+// ParseReconcileMode validates a reconcile mode given on the command line.
+func ParseReconcileMode(s string) (ReconcileMode, error) {
+	switch mode := ReconcileMode(s); mode {
+	case ReconcileModeUpdate, ReconcileModeApply:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown reconcile mode %q, must be %q or %q", s, ReconcileModeUpdate, ReconcileModeApply)
+}
+
+func (c *Controller) createDeployment(ctx context.Context, desired *appsv1.Deployment) (*appsv1.Deployment, error) {
+	if c.reconcileMode == ReconcileModeApply {
+		return c.applyDeployment(ctx, desired)
+	}
+	deployment, err := c.kubeclientset.AppsV1().Deployments(desired.Namespace).Create(ctx, desired, metav1.CreateOptions{})
+	if err == nil {
+		recordChildOperation("Deployment", operationCreate)
+	}
+	return deployment, err
+}
+
+func (c *Controller) updateDeployment(ctx context.Context, desired, updated *appsv1.Deployment) (*appsv1.Deployment, error) {
+	if c.reconcileMode == ReconcileModeApply {
+		return c.applyDeployment(ctx, desired)
+	}
+	deployment, err := c.kubeclientset.AppsV1().Deployments(updated.Namespace).Update(ctx, updated, metav1.UpdateOptions{})
+	if err == nil {
+		recordChildOperation("Deployment", operationUpdate)
+	}
+	return deployment, err
+}
+
+func (c *Controller) applyDeployment(ctx context.Context, desired *appsv1.Deployment) (*appsv1.Deployment, error) {
+	deployment, err := c.kubeclientset.AppsV1().Deployments(desired.Namespace).Apply(ctx, deploymentApplyConfiguration(desired), applyOptions())
+	if err == nil {
+		recordChildOperation("Deployment", operationApply)
+	}
+	return deployment, err
+}
+
+func (c *Controller) createHorizontalPodAutoscaler(ctx context.Context, desired *autoscalingv2.HorizontalPodAutoscaler) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	if c.reconcileMode == ReconcileModeApply {
+		return c.applyHorizontalPodAutoscaler(ctx, desired)
+	}
+	hpa, err := c.kubeclientset.AutoscalingV2().HorizontalPodAutoscalers(desired.Namespace).Create(ctx, desired, metav1.CreateOptions{})
+	if err == nil {
+		recordChildOperation("HorizontalPodAutoscaler", operationCreate)
+	}
+	return hpa, err
+}
+
+func (c *Controller) updateHorizontalPodAutoscaler(ctx context.Context, desired, updated *autoscalingv2.HorizontalPodAutoscaler) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	if c.reconcileMode == ReconcileModeApply {
+		return c.applyHorizontalPodAutoscaler(ctx, desired)
+	}
+	hpa, err := c.kubeclientset.AutoscalingV2().HorizontalPodAutoscalers(updated.Namespace).Update(ctx, updated, metav1.UpdateOptions{})
+	if err == nil {
+		recordChildOperation("HorizontalPodAutoscaler", operationUpdate)
+	}
+	return hpa, err
+}
+
+func (c *Controller) applyHorizontalPodAutoscaler(ctx context.Context, desired *autoscalingv2.HorizontalPodAutoscaler) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	hpa, err := c.kubeclientset.AutoscalingV2().HorizontalPodAutoscalers(desired.Namespace).Apply(ctx, horizontalPodAutoscalerApplyConfiguration(desired), applyOptions())
+	if err == nil {
+		recordChildOperation("HorizontalPodAutoscaler", operationApply)
+	}
+	return hpa, err
+}
+
+func (c *Controller) createService(ctx context.Context, desired *corev1.Service) (*corev1.Service, error) {
+	if c.reconcileMode == ReconcileModeApply {
+		return c.applyService(ctx, desired)
+	}
+	service, err := c.kubeclientset.CoreV1().Services(desired.Namespace).Create(ctx, desired, metav1.CreateOptions{})
+	if err == nil {
+		recordChildOperation("Service", operationCreate)
+	}
+	return service, err
+}
+
+func (c *Controller) updateService(ctx context.Context, desired, updated *corev1.Service) (*corev1.Service, error) {
+	if c.reconcileMode == ReconcileModeApply {
+		return c.applyService(ctx, desired)
+	}
+	service, err := c.kubeclientset.CoreV1().Services(updated.Namespace).Update(ctx, updated, metav1.UpdateOptions{})
+	if err == nil {
+		recordChildOperation("Service", operationUpdate)
+	}
+	return service, err
+}
+
+func (c *Controller) applyService(ctx context.Context, desired *corev1.Service) (*corev1.Service, error) {
+	service, err := c.kubeclientset.CoreV1().Services(desired.Namespace).Apply(ctx, serviceApplyConfiguration(desired), applyOptions())
+	if err == nil {
+		recordChildOperation("Service", operationApply)
+	}
+	return service, err
+}
+
+func (c *Controller) createIngress(ctx context.Context, desired *v1.Ingress) (*v1.Ingress, error) {
+	if c.reconcileMode == ReconcileModeApply {
+		return c.applyIngress(ctx, desired)
+	}
+	ingress, err := c.kubeclientset.NetworkingV1().Ingresses(desired.Namespace).Create(ctx, desired, metav1.CreateOptions{})
+	if err == nil {
+		recordChildOperation("Ingress", operationCreate)
+	}
+	return ingress, err
+}
+
+func (c *Controller) updateIngress(ctx context.Context, desired, updated *v1.Ingress) (*v1.Ingress, error) {
+	if c.reconcileMode == ReconcileModeApply {
+		return c.applyIngress(ctx, desired)
+	}
+	ingress, err := c.kubeclientset.NetworkingV1().Ingresses(updated.Namespace).Update(ctx, updated, metav1.UpdateOptions{})
+	if err == nil {
+		recordChildOperation("Ingress", operationUpdate)
+	}
+	return ingress, err
+}
+
+func (c *Controller) applyIngress(ctx context.Context, desired *v1.Ingress) (*v1.Ingress, error) {
+	ingress, err := c.kubeclientset.NetworkingV1().Ingresses(desired.Namespace).Apply(ctx, ingressApplyConfiguration(desired), applyOptions())
+	if err == nil {
+		recordChildOperation("Ingress", operationApply)
+	}
+	return ingress, err
+}
+
+// applyOptions forces conflicts: the App is the source of truth for the
+// fields the controller renders. Fields it leaves to others, like the
+// replicas of an autoscaled Deployment, aren't in the apply configurations.
+func applyOptions() metav1.ApplyOptions {
+	return metav1.ApplyOptions{FieldManager: fieldManager, Force: true}
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1ac "k8s.io/client-go/applyconfigurations/apps/v1"
+	autoscalingv2ac "k8s.io/client-go/applyconfigurations/autoscaling/v2"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+	networkingv1ac "k8s.io/client-go/applyconfigurations/networking/v1"
+)
+
+// The functions below turn the children rendered by newDeployment and
+// friends into apply configurations for server-side apply. Only the fields
+// the controller renders are set: zero values are left out rather than sent,
+// so that the controller doesn't take ownership of fields it leaves to the
+// API server or to other field managers.
+
+func deploymentApplyConfiguration(desired *appsv1.Deployment) *appsv1ac.DeploymentApplyConfiguration {
+	spec := appsv1ac.DeploymentSpec().
+		WithSelector(labelSelectorApplyConfiguration(desired.Spec.Selector)).
+		WithTemplate(corev1ac.PodTemplateSpec().
+			WithLabels(desired.Spec.Template.Labels).
+			WithSpec(podSpecApplyConfiguration(&desired.Spec.Template.Spec)))
+	// The replicas aren't rendered while a HorizontalPodAutoscaler scales the
+	// Deployment; applying them would take them back from it.
+	if desired.Spec.Replicas != nil {
+		spec.WithReplicas(*desired.Spec.Replicas)
+	}
+	return appsv1ac.Deployment(desired.Name, desired.Namespace).
+		WithLabels(desired.Labels).
+		WithAnnotations(desired.Annotations).
+		WithOwnerReferences(ownerReferenceApplyConfigurations(desired.OwnerReferences)...).
+		WithSpec(spec)
+}
+
+func podSpecApplyConfiguration(spec *corev1.PodSpec) *corev1ac.PodSpecApplyConfiguration {
+	podSpec := corev1ac.PodSpec()
+	for i := range spec.Containers {
+		podSpec.WithContainers(containerApplyConfiguration(&spec.Containers[i]))
+	}
+	for _, secret := range spec.ImagePullSecrets {
+		podSpec.WithImagePullSecrets(corev1ac.LocalObjectReference().WithName(secret.Name))
+	}
+	return podSpec
+}
+
+func containerApplyConfiguration(container *corev1.Container) *corev1ac.ContainerApplyConfiguration {
+	c := corev1ac.Container().
+		WithName(container.Name).
+		WithImage(container.Image).
+		WithCommand(container.Command...).
+		WithArgs(container.Args...)
+	for i := range container.Env {
+		c.WithEnv(envVarApplyConfiguration(&container.Env[i]))
+	}
+	for _, p := range container.Ports {
+		port := corev1ac.ContainerPort().WithContainerPort(p.ContainerPort).WithProtocol(p.Protocol)
+		if p.Name != "" {
+			port.WithName(p.Name)
+		}
+		if p.HostPort != 0 {
+			port.WithHostPort(p.HostPort)
+		}
+		if p.HostIP != "" {
+			port.WithHostIP(p.HostIP)
+		}
+		c.WithPorts(port)
+	}
+	if resources := container.Resources; len(resources.Limits) > 0 || len(resources.Requests) > 0 || len(resources.Claims) > 0 {
+		r := corev1ac.ResourceRequirements()
+		if len(resources.Limits) > 0 {
+			r.WithLimits(resources.Limits)
+		}
+		if len(resources.Requests) > 0 {
+			r.WithRequests(resources.Requests)
+		}
+		for _, claim := range resources.Claims {
+			r.WithClaims(corev1ac.ResourceClaim().WithName(claim.Name))
+		}
+		c.WithResources(r)
+	}
+	if container.LivenessProbe != nil {
+		c.WithLivenessProbe(probeApplyConfiguration(container.LivenessProbe))
+	}
+	if container.ReadinessProbe != nil {
+		c.WithReadinessProbe(probeApplyConfiguration(container.ReadinessProbe))
+	}
+	if container.StartupProbe != nil {
+		c.WithStartupProbe(probeApplyConfiguration(container.StartupProbe))
+	}
+	if container.ImagePullPolicy != "" {
+		c.WithImagePullPolicy(container.ImagePullPolicy)
+	}
+	return c
+}
+
+func envVarApplyConfiguration(env *corev1.EnvVar) *corev1ac.EnvVarApplyConfiguration {
+	e := corev1ac.EnvVar().WithName(env.Name)
+	if env.Value != "" {
+		e.WithValue(env.Value)
+	}
+	from := env.ValueFrom
+	if from == nil {
+		return e
+	}
+	source := corev1ac.EnvVarSource()
+	if ref := from.FieldRef; ref != nil {
+		source.WithFieldRef(corev1ac.ObjectFieldSelector().WithAPIVersion(ref.APIVersion).WithFieldPath(ref.FieldPath))
+	}
+	if ref := from.ResourceFieldRef; ref != nil {
+		selector := corev1ac.ResourceFieldSelector().WithResource(ref.Resource)
+		if ref.ContainerName != "" {
+			selector.WithContainerName(ref.ContainerName)
+		}
+		if !ref.Divisor.IsZero() {
+			selector.WithDivisor(ref.Divisor)
+		}
+		source.WithResourceFieldRef(selector)
+	}
+	if ref := from.ConfigMapKeyRef; ref != nil {
+		selector := corev1ac.ConfigMapKeySelector().WithName(ref.Name).WithKey(ref.Key)
+		if ref.Optional != nil {
+			selector.WithOptional(*ref.Optional)
+		}
+		source.WithConfigMapKeyRef(selector)
+	}
+	if ref := from.SecretKeyRef; ref != nil {
+		selector := corev1ac.SecretKeySelector().WithName(ref.Name).WithKey(ref.Key)
+		if ref.Optional != nil {
+			selector.WithOptional(*ref.Optional)
+		}
+		source.WithSecretKeyRef(selector)
+	}
+	return e.WithValueFrom(source)
+}
+
+func probeApplyConfiguration(probe *corev1.Probe) *corev1ac.ProbeApplyConfiguration {
+	p := corev1ac.Probe().
+		WithTimeoutSeconds(probe.TimeoutSeconds).
+		WithPeriodSeconds(probe.PeriodSeconds).
+		WithSuccessThreshold(probe.SuccessThreshold).
+		WithFailureThreshold(probe.FailureThreshold)
+	if probe.InitialDelaySeconds != 0 {
+		p.WithInitialDelaySeconds(probe.InitialDelaySeconds)
+	}
+	if probe.TerminationGracePeriodSeconds != nil {
+		p.WithTerminationGracePeriodSeconds(*probe.TerminationGracePeriodSeconds)
+	}
+	if action := probe.Exec; action != nil {
+		p.WithExec(corev1ac.ExecAction().WithCommand(action.Command...))
+	}
+	if action := probe.HTTPGet; action != nil {
+		httpGet := corev1ac.HTTPGetAction().WithPort(action.Port).WithScheme(action.Scheme)
+		if action.Path != "" {
+			httpGet.WithPath(action.Path)
+		}
+		if action.Host != "" {
+			httpGet.WithHost(action.Host)
+		}
+		for _, header := range action.HTTPHeaders {
+			httpGet.WithHTTPHeaders(corev1ac.HTTPHeader().WithName(header.Name).WithValue(header.Value))
+		}
+		p.WithHTTPGet(httpGet)
+	}
+	if action := probe.TCPSocket; action != nil {
+		tcpSocket := corev1ac.TCPSocketAction().WithPort(action.Port)
+		if action.Host != "" {
+			tcpSocket.WithHost(action.Host)
+		}
+		p.WithTCPSocket(tcpSocket)
+	}
+	if action := probe.GRPC; action != nil {
+		grpc := corev1ac.GRPCAction().WithPort(action.Port)
+		if action.Service != nil {
+			grpc.WithService(*action.Service)
+		}
+		p.WithGRPC(grpc)
+	}
+	return p
+}
+
+func horizontalPodAutoscalerApplyConfiguration(desired *autoscalingv2.HorizontalPodAutoscaler) *autoscalingv2ac.HorizontalPodAutoscalerApplyConfiguration {
+	target := desired.Spec.ScaleTargetRef
+	spec := autoscalingv2ac.HorizontalPodAutoscalerSpec().
+		WithScaleTargetRef(autoscalingv2ac.CrossVersionObjectReference().
+			WithAPIVersion(target.APIVersion).
+			WithKind(target.Kind).
+			WithName(target.Name)).
+		WithMaxReplicas(desired.Spec.MaxReplicas)
+	if desired.Spec.MinReplicas != nil {
+		spec.WithMinReplicas(*desired.Spec.MinReplicas)
+	}
+	for _, m := range desired.Spec.Metrics {
+		metric := autoscalingv2ac.MetricSpec().WithType(m.Type)
+		if source := m.Resource; source != nil {
+			metric.WithResource(autoscalingv2ac.ResourceMetricSource().
+				WithName(source.Name).
+				WithTarget(metricTargetApplyConfiguration(source.Target)))
+		}
+		if source := m.Pods; source != nil {
+			identifier := autoscalingv2ac.MetricIdentifier().WithName(source.Metric.Name)
+			if source.Metric.Selector != nil {
+				identifier.WithSelector(labelSelectorApplyConfiguration(source.Metric.Selector))
+			}
+			metric.WithPods(autoscalingv2ac.PodsMetricSource().
+				WithMetric(identifier).
+				WithTarget(metricTargetApplyConfiguration(source.Target)))
+		}
+		spec.WithMetrics(metric)
+	}
+	return autoscalingv2ac.HorizontalPodAutoscaler(desired.Name, desired.Namespace).
+		WithLabels(desired.Labels).
+		WithAnnotations(desired.Annotations).
+		WithOwnerReferences(ownerReferenceApplyConfigurations(desired.OwnerReferences)...).
+		WithSpec(spec)
+}
+
+func metricTargetApplyConfiguration(target autoscalingv2.MetricTarget) *autoscalingv2ac.MetricTargetApplyConfiguration {
+	t := autoscalingv2ac.MetricTarget().WithType(target.Type)
+	if target.Value != nil {
+		t.WithValue(*target.Value)
+	}
+	if target.AverageValue != nil {
+		t.WithAverageValue(*target.AverageValue)
+	}
+	if target.AverageUtilization != nil {
+		t.WithAverageUtilization(*target.AverageUtilization)
+	}
+	return t
+}
+
+func serviceApplyConfiguration(desired *corev1.Service) *corev1ac.ServiceApplyConfiguration {
+	spec := corev1ac.ServiceSpec().
+		WithSelector(desired.Spec.Selector).
+		WithType(desired.Spec.Type).
+		WithSessionAffinity(desired.Spec.SessionAffinity)
+	if desired.Spec.ClusterIP != "" {
+		spec.WithClusterIP(desired.Spec.ClusterIP)
+	}
+	for _, p := range desired.Spec.Ports {
+		port := corev1ac.ServicePort().
+			WithProtocol(p.Protocol).
+			WithPort(p.Port).
+			WithTargetPort(p.TargetPort)
+		if p.Name != "" {
+			port.WithName(p.Name)
+		}
+		if p.NodePort != 0 {
+			port.WithNodePort(p.NodePort)
+		}
+		spec.WithPorts(port)
+	}
+	return corev1ac.Service(desired.Name, desired.Namespace).
+		WithLabels(desired.Labels).
+		WithAnnotations(desired.Annotations).
+		WithOwnerReferences(ownerReferenceApplyConfigurations(desired.OwnerReferences)...).
+		WithSpec(spec)
+}
+
+func ingressApplyConfiguration(desired *v1.Ingress) *networkingv1ac.IngressApplyConfiguration {
+	spec := networkingv1ac.IngressSpec()
+	if desired.Spec.IngressClassName != nil {
+		spec.WithIngressClassName(*desired.Spec.IngressClassName)
+	}
+	for _, rule := range desired.Spec.Rules {
+		r := networkingv1ac.IngressRule()
+		if rule.Host != "" {
+			r.WithHost(rule.Host)
+		}
+		if rule.HTTP != nil {
+			http := networkingv1ac.HTTPIngressRuleValue()
+			for _, p := range rule.HTTP.Paths {
+				http.WithPaths(httpIngressPathApplyConfiguration(p))
+			}
+			r.WithHTTP(http)
+		}
+		spec.WithRules(r)
+	}
+	for _, tls := range desired.Spec.TLS {
+		t := networkingv1ac.IngressTLS().WithHosts(tls.Hosts...)
+		if tls.SecretName != "" {
+			t.WithSecretName(tls.SecretName)
+		}
+		spec.WithTLS(t)
+	}
+	return networkingv1ac.Ingress(desired.Name, desired.Namespace).
+		WithLabels(desired.Labels).
+		WithAnnotations(desired.Annotations).
+		WithOwnerReferences(ownerReferenceApplyConfigurations(desired.OwnerReferences)...).
+		WithSpec(spec)
+}
+
+func httpIngressPathApplyConfiguration(p v1.HTTPIngressPath) *networkingv1ac.HTTPIngressPathApplyConfiguration {
+	path := networkingv1ac.HTTPIngressPath().WithPath(p.Path)
+	if p.PathType != nil {
+		path.WithPathType(*p.PathType)
+	}
+	if service := p.Backend.Service; service != nil {
+		port := networkingv1ac.ServiceBackendPort()
+		if service.Port.Name != "" {
+			port.WithName(service.Port.Name)
+		} else {
+			port.WithNumber(service.Port.Number)
+		}
+		path.WithBackend(networkingv1ac.IngressBackend().
+			WithService(networkingv1ac.IngressServiceBackend().WithName(service.Name).WithPort(port)))
+	}
+	return path
+}
+
+func labelSelectorApplyConfiguration(selector *metav1.LabelSelector) *metav1ac.LabelSelectorApplyConfiguration {
+	s := metav1ac.LabelSelector().WithMatchLabels(selector.MatchLabels)
+	for _, requirement := range selector.MatchExpressions {
+		s.WithMatchExpressions(metav1ac.LabelSelectorRequirement().
+			WithKey(requirement.Key).
+			WithOperator(requirement.Operator).
+			WithValues(requirement.Values...))
+	}
+	return s
+}
+
+func ownerReferenceApplyConfigurations(refs []metav1.OwnerReference) []*metav1ac.OwnerReferenceApplyConfiguration {
+	applied := make([]*metav1ac.OwnerReferenceApplyConfiguration, 0, len(refs))
+	for _, ref := range refs {
+		r := metav1ac.OwnerReference().
+			WithAPIVersion(ref.APIVersion).
+			WithKind(ref.Kind).
+			WithName(ref.Name).
+			WithUID(ref.UID)
+		if ref.Controller != nil {
+			r.WithController(*ref.Controller)
+		}
+		if ref.BlockOwnerDeletion != nil {
+			r.WithBlockOwnerDeletion(*ref.BlockOwnerDeletion)
+		}
+		applied = append(applied, r)
+	}
+	return applied
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	groupkindv1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// newFullFoo returns a App using every field the controller renders into
+// its children.
+func newFullFoo() *groupkindv1alpha1.Foo {
+	foo := withRoute(newFoo("test", 3), "example.com", "/api")
+	foo.Labels["team"] = "web"
+	optional := true
+	foo.Spec.Deployment.Command = []string{"nginx"}
+	foo.Spec.Deployment.Args = []string{"-g", "daemon off;"}
+	foo.Spec.Deployment.Env = []corev1.EnvVar{
+		{Name: "MODE", Value: "production"},
+		{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}}},
+		{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "token"}, Key: "value", Optional: &optional,
+		}}},
+	}
+	foo.Spec.Deployment.Ports = []corev1.ContainerPort{{Name: "http", ContainerPort: 80}}
+	foo.Spec.Deployment.Resources = corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+	}
+	foo.Spec.Deployment.LivenessProbe = &corev1.Probe{
+		ProbeHandler:        corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromString("http")}},
+		InitialDelaySeconds: 5,
+	}
+	foo.Spec.Deployment.ReadinessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(80)}},
+	}
+	foo.Spec.Deployment.ImagePullPolicy = corev1.PullAlways
+	foo.Spec.Deployment.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry"}}
+	foo.Spec.Service.Type = groupkindv1alpha1.ServiceTypeNodePort
+	foo.Spec.Service.Annotations = map[string]string{"example.com/scrape": "true"}
+	className := "nginx"
+	foo.Spec.Ingress.IngressClassName = &className
+	foo.Spec.Ingress.TLS = []v1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-tls"}}
+	withAutoscaling(foo, 2, 10)
+	foo.Spec.Autoscaling.CustomMetrics = []groupkindv1alpha1.CustomMetricTarget{{
+		Name:         "requests_per_second",
+		Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"verb": "GET"}},
+		AverageValue: resource.MustParse("100"),
+	}}
+	return foo
+}
+
+// roundTrip decodes the JSON of an apply configuration into the typed
+// object it configures.
+func roundTrip(t *testing.T, applyConfiguration interface{}, into runtime.Object) runtime.Object {
+	t.Helper()
+	data, err := json.Marshal(applyConfiguration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, into); err != nil {
+		t.Fatal(err)
+	}
+	return into
+}
+
+func TestApplyConfigurationsMatchRenderedChildren(t *testing.T) {
+	foo := newFullFoo()
+	// The replicas are applied when the Deployment isn't autoscaled.
+	unscaled := foo.DeepCopy()
+	unscaled.Spec.Autoscaling = nil
+
+	tests := []struct {
+		name     string
+		desired  runtime.Object
+		applied  interface{}
+		into     runtime.Object
+		typeMeta metav1.TypeMeta
+	}{
+		{
+			name:     "deployment",
+			desired:  newDeployment(foo),
+			applied:  deploymentApplyConfiguration(newDeployment(foo)),
+			into:     &appsv1.Deployment{},
+			typeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		},
+		{
+			name:     "deployment with replicas",
+			desired:  newDeployment(unscaled),
+			applied:  deploymentApplyConfiguration(newDeployment(unscaled)),
+			into:     &appsv1.Deployment{},
+			typeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		},
+		{
+			name:     "horizontal pod autoscaler",
+			desired:  newHorizontalPodAutoscaler(foo),
+			applied:  horizontalPodAutoscalerApplyConfiguration(newHorizontalPodAutoscaler(foo)),
+			into:     &autoscalingv2.HorizontalPodAutoscaler{},
+			typeMeta: metav1.TypeMeta{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler"},
+		},
+		{
+			name:     "service",
+			desired:  newService(foo),
+			applied:  serviceApplyConfiguration(newService(foo)),
+			into:     &corev1.Service{},
+			typeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		},
+		{
+			name:     "ingress",
+			desired:  newIngress(foo),
+			applied:  ingressApplyConfiguration(newIngress(foo)),
+			into:     &v1.Ingress{},
+			typeMeta: metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := tt.desired.DeepCopyObject()
+			expected.GetObjectKind().SetGroupVersionKind(tt.typeMeta.GroupVersionKind())
+			applied := roundTrip(t, tt.applied, tt.into)
+			if !equality.Semantic.DeepEqual(expected, applied) {
+				t.Errorf("expected the apply configuration to render the %s\nDiff:\n %s", tt.name, diff.ObjectGoPrintSideBySide(expected, applied))
+			}
+		})
+	}
+}
+
+func TestApplyConfigurationLeavesAutoscaledReplicasOut(t *testing.T) {
+	applied := deploymentApplyConfiguration(newDeployment(withAutoscaling(newFoo("test", 3), 2, 10)))
+	if applied.Spec.Replicas != nil {
+		t.Errorf("expected the replicas of an autoscaled deployment to be left out, got %d", *applied.Spec.Replicas)
+	}
+	data, err := json.Marshal(applied)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"replicas"`, `"status"`, `"creationTimestamp"`, `"strategy"`, `"resources"`} {
+		if strings.Contains(string(data), field) {
+			t.Errorf("expected %s to be left out of the apply configuration, got %s", field, data)
+		}
+	}
+}
@@ -6,25 +6,29 @@ import (
 	clientset "controller-crd/pkg/generated/clientset/versioned"
 	"controller-crd/pkg/generated/clientset/versioned/scheme"
 	groupkindscheme "controller-crd/pkg/generated/clientset/versioned/scheme"
-	groupkindinformer "controller-crd/pkg/generated/informers/externalversions/groupkind/v1alpha1"
 	groupkindlister "controller-crd/pkg/generated/listers/groupkind/v1alpha1"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
-	v12 "k8s.io/client-go/informers/apps/v1"
-	v13 "k8s.io/client-go/informers/core/v1"
-	v14 "k8s.io/client-go/informers/networking/v1"
 	"k8s.io/client-go/kubernetes"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	v15 "k8s.io/client-go/listers/apps/v1"
+	autoscalingv2listers "k8s.io/client-go/listers/autoscaling/v2"
 	v16 "k8s.io/client-go/listers/core/v1"
 	v17 "k8s.io/client-go/listers/networking/v1"
 	"k8s.io/client-go/tools/cache"
@@ -55,10 +59,12 @@ type Controller struct {
 	kubeclientset kubernetes.Interface
 	// appclientset is a clientset for our own API group
 	groupkindclientset clientset.Interface
-	deploymentsSynced  cache.InformerSynced
-	serviceSynced      cache.InformerSynced
-	ingressSynced      cache.InformerSynced
-	appsSynced         cache.InformerSynced
+	// informersSynced are the informers the workers wait on before
+	// starting, one per resource and watched namespace.
+	informersSynced []informerSynced
+	// cacheSyncTimeout bounds the wait for informersSynced, zero meaning no
+	// bound.
+	cacheSyncTimeout time.Duration
 
 	// workqueue is a rate limited work queue. This is used to queue work to be
 	// processed instead of performing it as soon as a change happens. This
@@ -74,18 +80,38 @@ type Controller struct {
 	serviceLister      v16.ServiceLister
 	ingressLister      v17.IngressLister
 	foosLister         groupkindlister.FooLister
-	foosSynced         func() bool
+	hpaLister          autoscalingv2listers.HorizontalPodAutoscalerLister
+	// reconcileMode selects how children are written to the API server.
+	reconcileMode ReconcileMode
+
+	// synced is set to 1 once the informer caches have synced.
+	synced int32
+	// lastDequeue is the time, in unix nanoseconds, a worker last took an
+	// item off the workqueue or the workers were started.
+	lastDequeue int64
+}
+
+// ControllerOptions holds the settings of a Controller that don't come from
+// its clients and informers.
+type ControllerOptions struct {
+	// ReconcileMode selects how children are written. Defaults to
+	// ReconcileModeUpdate.
+	ReconcileMode ReconcileMode
+	// CacheSyncTimeout is how long Run waits for the informer caches to
+	// sync before giving up. Zero waits until the stop channel is closed.
+	CacheSyncTimeout time.Duration
 }
 
 const controllerAgentName = "controller-crd"
 
+// NewController returns a controller syncing the Apps of the namespaces
+// watched by informers. Pass a single Informers of metav1.NamespaceAll to
+// watch the whole cluster.
 func NewController(
 	kubeclientset kubernetes.Interface,
 	groupkindClientset clientset.Interface,
-	depoymentInformer v12.DeploymentInformer,
-	serviceInformer v13.ServiceInformer,
-	ingressInformer v14.IngressInformer,
-	groupkindInformer groupkindinformer.FooInformer) *Controller {
+	informers []Informers,
+	opts ControllerOptions) *Controller {
 
 	// Create event broadcaster
 	// Add app-controller types to the default Kubernetes Scheme so Events can be
@@ -97,29 +123,84 @@ func NewController(
 	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeclientset.CoreV1().Events("")})
 	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: controllerAgentName})
 
+	deploymentsLister := namespacedDeploymentLister{}
+	serviceLister := namespacedServiceLister{}
+	ingressLister := namespacedIngressLister{}
+	foosLister := namespacedFooLister{}
+	hpaLister := namespacedHorizontalPodAutoscalerLister{}
+	var informersSynced []informerSynced
+	for _, i := range informers {
+		deploymentsLister[i.Namespace] = i.Deployments.Lister()
+		serviceLister[i.Namespace] = i.Services.Lister()
+		ingressLister[i.Namespace] = i.Ingresses.Lister()
+		foosLister[i.Namespace] = i.Foos.Lister()
+		hpaLister[i.Namespace] = i.HorizontalPodAutoscalers.Lister()
+		informersSynced = append(informersSynced, i.synced()...)
+	}
+
 	controller := &Controller{
 		kubeclientset:      kubeclientset,
 		groupkindClientset: groupkindClientset,
-		deploymentsLister:  depoymentInformer.Lister(),
-		deploymentsSynced:  depoymentInformer.Informer().HasSynced,
-		serviceLister:      serviceInformer.Lister(),
-		serviceSynced:      serviceInformer.Informer().HasSynced,
-		ingressLister:      ingressInformer.Lister(),
-		ingressSynced:      ingressInformer.Informer().HasSynced,
-		foosLister:         groupkindInformer.Lister(),
-		foosSynced:         groupkindInformer.Informer().HasSynced,
+		deploymentsLister:  deploymentsLister,
+		serviceLister:      serviceLister,
+		ingressLister:      ingressLister,
+		foosLister:         foosLister,
+		hpaLister:          hpaLister,
+		informersSynced:    informersSynced,
+		cacheSyncTimeout:   opts.CacheSyncTimeout,
 		workqueue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "Apps"),
 		recorder:           recorder,
+		reconcileMode:      opts.ReconcileMode,
+	}
+	if controller.reconcileMode == "" {
+		controller.reconcileMode = ReconcileModeUpdate
 	}
 
 	klog.Info("Setting up event handlers")
 	// Set up an event handler for when App resources change
-	groupkindInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+	fooHandler := cache.ResourceEventHandlerFuncs{
 		AddFunc: controller.enqueueApp,
 		UpdateFunc: func(old, new interface{}) {
 			controller.enqueueApp(new)
 		},
-	})
+	}
+	// Set up an event handler for when Deployment, HorizontalPodAutoscaler,
+	// Service and Ingress resources change. This handler will lookup the owner of the given
+	// object, and if it is owned by a App resource then the handler will
+	// enqueue that App resource for processing. This way, we don't need to
+	// implement custom logic for handling child resources. More info on this
+	// pattern:
+	// https://github.com/kubernetes/community/blob/8cafef897a22026d42f5e5bb3f104febe7e29830/contributors/devel/controllers.md
+	childHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handleObject,
+		UpdateFunc: func(old, new interface{}) {
+			newMeta, err := meta.Accessor(new)
+			if err != nil {
+				utilruntime.HandleError(err)
+				return
+			}
+			oldMeta, err := meta.Accessor(old)
+			if err != nil {
+				utilruntime.HandleError(err)
+				return
+			}
+			if newMeta.GetResourceVersion() == oldMeta.GetResourceVersion() {
+				// Periodic resync will send update events for all known
+				// children. Two different versions of the same object
+				// will always have different RVs.
+				return
+			}
+			controller.handleObject(new)
+		},
+		DeleteFunc: controller.handleObject,
+	}
+	for _, i := range informers {
+		i.Foos.Informer().AddEventHandler(fooHandler)
+		i.Deployments.Informer().AddEventHandler(childHandler)
+		i.Services.Informer().AddEventHandler(childHandler)
+		i.Ingresses.Informer().AddEventHandler(childHandler)
+		i.HorizontalPodAutoscalers.Informer().AddEventHandler(childHandler)
+	}
 
 	return controller
 }
@@ -135,9 +216,14 @@ func (c *Controller) Run(workers int, stopCh <-chan struct{}) error {
 	// Wait for the caches to be synced before starting workers
 	klog.Info("Waiting for informer caches to sync")
 	//wait 这些资源再list里都同步完成
-	if ok := cache.WaitForCacheSync(stopCh, c.deploymentsSynced, c.appsSynced, c.serviceSynced, c.ingressSynced); !ok {
-		return fmt.Errorf("failed to wait for caches to sync")
+	if err := c.waitForCacheSync(stopCh); err != nil {
+		return err
+	}
+	if err := c.migrateOwnerReferences(context.TODO()); err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to migrate owner references: %s", err.Error()))
 	}
+	atomic.StoreInt32(&c.synced, 1)
+	atomic.StoreInt64(&c.lastDequeue, time.Now().UnixNano())
 
 	klog.Info("Starting workers")
 	// Launch two workers to process App resources
@@ -152,6 +238,77 @@ func (c *Controller) Run(workers int, stopCh <-chan struct{}) error {
 	return nil
 }
 
+// cacheSyncPollInterval is how often waitForCacheSync checks the informers,
+// the same as cache.WaitForCacheSync.
+const cacheSyncPollInterval = 100 * time.Millisecond
+
+// waitForCacheSync waits until every informer has synced, stopCh is closed or
+// the cache sync timeout expires. The error names the informers that did not
+// sync.
+func (c *Controller) waitForCacheSync(stopCh <-chan struct{}) error {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(stop)
+		var timeout <-chan time.Time
+		if c.cacheSyncTimeout > 0 {
+			timer := time.NewTimer(c.cacheSyncTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case <-stopCh:
+		case <-timeout:
+		case <-done:
+		}
+	}()
+
+	err := wait.PollImmediateUntil(cacheSyncPollInterval, func() (bool, error) {
+		return len(c.unsyncedInformers()) == 0, nil
+	}, stop)
+	if err == nil {
+		return nil
+	}
+	unsynced := strings.Join(c.unsyncedInformers(), ", ")
+	select {
+	case <-stopCh:
+		return fmt.Errorf("stopped before caches synced, waiting on %s", unsynced)
+	default:
+		return fmt.Errorf("failed to wait for caches to sync within %s, waiting on %s", c.cacheSyncTimeout, unsynced)
+	}
+}
+
+// unsyncedInformers returns the names of the informers that haven't synced.
+func (c *Controller) unsyncedInformers() []string {
+	var names []string
+	for _, i := range c.informersSynced {
+		if !i.synced() {
+			names = append(names, i.name)
+		}
+	}
+	return names
+}
+
+// cachesSynced reports whether the informer caches have synced and the
+// workers were started.
+func (c *Controller) cachesSynced() bool {
+	return atomic.LoadInt32(&c.synced) == 1
+}
+
+// checkWorkers returns an error when items are waiting in the workqueue but
+// no worker took one off it for longer than timeout.
+func (c *Controller) checkWorkers(timeout time.Duration) error {
+	if !c.cachesSynced() || c.workqueue.Len() == 0 {
+		return nil
+	}
+	last := time.Unix(0, atomic.LoadInt64(&c.lastDequeue))
+	if stalled := time.Since(last); stalled > timeout {
+		return fmt.Errorf("%d items queued but no worker dequeued for %s", c.workqueue.Len(), stalled.Round(time.Second))
+	}
+	return nil
+}
+
 // runWorker is a long-running function that will continually call the
 // processNextWorkItem function in order to read and process a message on the
 // workqueue.
@@ -169,6 +326,7 @@ func (c *Controller) processNextWorkItem() bool {
 	if shutdown {
 		return false
 	}
+	atomic.StoreInt64(&c.lastDequeue, time.Now().UnixNano())
 
 	err := func(obj interface{}) error {
 
@@ -183,7 +341,10 @@ func (c *Controller) processNextWorkItem() bool {
 			return nil
 		}
 
-		if err := c.syncHandler(key); err != nil {
+		start := time.Now()
+		err := c.syncHandler(key)
+		recordReconcile(start, err)
+		if err != nil {
 			c.workqueue.AddRateLimited(key)
 			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
 		}
@@ -223,52 +384,288 @@ func (c *Controller) syncHandler(key string) error {
 		return err
 	}
 
-	deployment, err := c.deploymentsLister.Deployments(foo.Namespace).Get(foo.Spec.Deployment.Name)
-	// If the resource doesn't exist, we'll create it
-	if errors.IsNotFound(err) {
-		deployment, err = c.kubeclientset.AppsV1().Deployments(foo.Namespace).Create(context.TODO(), newDeployment(foo), metav1.CreateOptions{})
+	// Fill in the defaults of the App the way the defaulting webhook would
+	// have stored them, so that Apps created without the webhook installed
+	// render the same children.
+	foo = withDefaults(foo)
+
+	// A App being deleted is torn down instead of reconciled.
+	if !foo.DeletionTimestamp.IsZero() {
+		return c.syncDeletion(key, foo)
 	}
+	foo, err = c.syncFinalizer(foo)
 	if err != nil {
 		return err
 	}
 
-	service, err := c.serviceLister.Services(foo.Namespace).Get(foo.Spec.Service.Name)
-	if errors.IsNotFound(err) {
-		service, err = c.kubeclientset.CoreV1().Services(foo.Namespace).Create(context.TODO(), newService(foo), metav1.CreateOptions{})
+	deployment, err := c.syncDeployment(key, foo)
+	if err != nil {
+		return err
 	}
+	hpa, err := c.syncHorizontalPodAutoscaler(key, foo)
 	if err != nil {
 		return err
 	}
-
-	ingress, err := c.ingressLister.Ingresses(foo.Namespace).Get(foo.Spec.Ingress.Name)
-	if errors.IsNotFound(err) {
-		ingress, err = c.kubeclientset.NetworkingV1().Ingresses(foo.Namespace).Create(context.TODO(), newIngress(foo), metav1.CreateOptions{})
+	service, err := c.syncService(key, foo)
+	if err != nil {
+		return err
+	}
+	ingress, err := c.syncIngress(key, foo)
+	if err != nil {
+		return err
 	}
 
+	// Finally, we update the status block of the App resource to reflect the
+	// current state of the world
+	err = c.updateFooStatus(foo, deployment, hpa, service, ingress)
 	if err != nil {
 		return err
 	}
 
+	c.recorder.Event(foo, corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+	return nil
+}
+
+// syncDeployment creates the Deployment of a App or brings it back in line
+// with the App spec.
+func (c *Controller) syncDeployment(key string, foo *groupkindv1alpha1.Foo) (*appsv1.Deployment, error) {
+	desired := newDeployment(foo)
+	deployment, err := c.deploymentsLister.Deployments(foo.Namespace).Get(foo.Spec.Deployment.Name)
+	switch {
+	// If the resource doesn't exist, we'll create it
+	case errors.IsNotFound(err):
+		deployment, err = c.createDeployment(context.TODO(), desired)
+	case err != nil:
+		return nil, err
 	// If the Deployment is not controlled by this App resource, we should log
 	// a warning to the event recorder and return error msg.
-	if !metav1.IsControlledBy(deployment, foo) {
-		msg := fmt.Sprintf(MessageResourceExists, deployment.Name)
-		c.recorder.Event(foo, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return fmt.Errorf("%s", msg)
+	case !metav1.IsControlledBy(deployment, foo):
+		return nil, c.resourceExists(foo, deployment.Name)
+	default:
+		// If the Deployment has drifted from what the App resource describes,
+		// either because the App spec changed or because someone edited it by
+		// hand, we update it. Fields we don't render (server defaults, fields
+		// owned by other controllers) are left as they are. In apply mode the
+		// desired object is server-side applied instead.
+		if updated, changed := reconcileDeployment(desired, deployment); changed {
+			klog.V(4).Infof("App %s deployment %s has drifted, updating", key, deployment.Name)
+			deployment, err = c.updateDeployment(context.TODO(), desired, updated)
+		}
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A renamed Deployment is replaced: the Deployments the App owns under
+	// another name are deleted once the new one is available, so that the
+	// App keeps serving in between. Until then the rename is reported as
+	// pending in the App status, and the status update of the new
+	// Deployment requeues the App.
+	if deploymentAvailable(deployment) {
+		if err := c.deleteOwnedDeployments(foo, deployment.Name); err != nil {
+			return nil, err
+		}
+	}
+	return deployment, nil
+}
+
+// syncHorizontalPodAutoscaler creates the HorizontalPodAutoscaler of a App
+// or brings it back in line with the App spec. When the App isn't
+// autoscaled, the HorizontalPodAutoscalers it owns are deleted and nil is
+// returned.
+func (c *Controller) syncHorizontalPodAutoscaler(key string, foo *groupkindv1alpha1.Foo) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	if !autoscalingEnabled(foo) {
+		return nil, c.deleteOwnedHorizontalPodAutoscalers(foo, "")
+	}
+
+	// The HorizontalPodAutoscaler is named after the Deployment it scales,
+	// so it is replaced when the Deployment is renamed. The old Deployment
+	// keeps its replicas until the new one is available.
+	if err := c.deleteOwnedHorizontalPodAutoscalers(foo, foo.Spec.Deployment.Name); err != nil {
+		return nil, err
+	}
+
+	desired := newHorizontalPodAutoscaler(foo)
+	hpa, err := c.hpaLister.HorizontalPodAutoscalers(foo.Namespace).Get(desired.Name)
+	if errors.IsNotFound(err) {
+		return c.createHorizontalPodAutoscaler(context.TODO(), desired)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !metav1.IsControlledBy(hpa, foo) {
+		return nil, c.resourceExists(foo, hpa.Name)
+	}
+
+	if updated, changed := reconcileHorizontalPodAutoscaler(desired, hpa); changed {
+		klog.V(4).Infof("App %s horizontal pod autoscaler %s has drifted, updating", key, hpa.Name)
+		return c.updateHorizontalPodAutoscaler(context.TODO(), desired, updated)
+	}
+	return hpa, nil
+}
+
+// syncService creates the Service of a App or brings it back in line with
+// the App spec. When the App has no Service, the Services it owns are deleted
+// and nil is returned.
+func (c *Controller) syncService(key string, foo *groupkindv1alpha1.Foo) (*corev1.Service, error) {
+	if !serviceEnabled(foo) {
+		return nil, c.deleteOwnedServices(foo, "")
+	}
+
+	// A renamed Service is replaced: the Services the App owns under another
+	// name are deleted and the new one is created.
+	if err := c.deleteOwnedServices(foo, serviceName(foo)); err != nil {
+		return nil, err
+	}
+
+	desired := newService(foo)
+	service, err := c.serviceLister.Services(foo.Namespace).Get(desired.Name)
+	if errors.IsNotFound(err) {
+		return c.createService(context.TODO(), desired)
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	if !metav1.IsControlledBy(service, foo) {
-		msg := fmt.Sprintf(MessageResourceExists, service.Name)
-		c.recorder.Event(foo, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return fmt.Errorf("%s", msg)
+		return nil, c.resourceExists(foo, service.Name)
+	}
+
+	if serviceNeedsRecreate(desired, service) {
+		// The cluster IP of a Service is immutable, so switching to or from
+		// a headless Service means deleting it and creating it again on the
+		// next sync.
+		klog.V(4).Infof("App %s service %s changes headless mode, recreating", key, service.Name)
+		err = c.kubeclientset.CoreV1().Services(foo.Namespace).Delete(context.TODO(), service.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		recordChildOperation("Service", operationDelete)
+		return nil, fmt.Errorf("service %q is being recreated", service.Name)
+	}
+	if updated, changed := reconcileService(desired, service); changed {
+		klog.V(4).Infof("App %s service %s has drifted, updating", key, service.Name)
+		return c.updateService(context.TODO(), desired, updated)
+	}
+	return service, nil
+}
+
+// syncIngress creates the Ingress of a App or brings it back in line with
+// the App spec. When the App has no Ingress, the Ingresses it owns are
+// deleted and nil is returned.
+func (c *Controller) syncIngress(key string, foo *groupkindv1alpha1.Foo) (*v1.Ingress, error) {
+	if !ingressEnabled(foo) {
+		return nil, c.deleteOwnedIngresses(foo, "")
+	}
+
+	// Two Apps must not route the same host and path, or the ingress
+	// controller would pick one of them arbitrarily.
+	if conflict := c.findIngressConflict(foo); conflict != nil {
+		msg := conflict.String()
+		c.recorder.Event(foo, corev1.EventTypeWarning, ErrHostConflict, msg)
+		c.updateFooStatusFailure(foo, ErrHostConflict, msg)
+		return nil, fmt.Errorf("%s", msg)
 	}
+
+	// A renamed Ingress is replaced like a renamed Service.
+	if err := c.deleteOwnedIngresses(foo, ingressName(foo)); err != nil {
+		return nil, err
+	}
+
+	desired := newIngress(foo)
+	ingress, err := c.ingressLister.Ingresses(foo.Namespace).Get(desired.Name)
+	if errors.IsNotFound(err) {
+		return c.createIngress(context.TODO(), desired)
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	if !metav1.IsControlledBy(ingress, foo) {
-		msg := fmt.Sprintf(MessageResourceExists, ingress.Name)
-		c.recorder.Event(foo, corev1.EventTypeWarning, ErrResourceExists, msg)
-		return fmt.Errorf("%s", msg)
+		return nil, c.resourceExists(foo, ingress.Name)
 	}
 
-	c.recorder.Event(foo, corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
-	return nil
+	if updated, changed := reconcileIngress(desired, ingress); changed {
+		klog.V(4).Infof("App %s ingress %s has drifted, updating", key, ingress.Name)
+		return c.updateIngress(context.TODO(), desired, updated)
+	}
+	return ingress, nil
+}
+
+// resourceExists reports a child that exists but is not controlled by the
+// App and returns the error to fail the sync with.
+func (c *Controller) resourceExists(foo *groupkindv1alpha1.Foo, name string) error {
+	msg := fmt.Sprintf(MessageResourceExists, name)
+	c.recorder.Event(foo, corev1.EventTypeWarning, ErrResourceExists, msg)
+	c.updateFooStatusFailure(foo, ErrResourceExists, msg)
+	return fmt.Errorf("%s", msg)
+}
+
+// updateFooStatus computes the status of the App resource from its children
+// and persists it through the status subresource. Nothing is written when the
+// status is already up to date, so a sync doesn't retrigger itself.
+func (c *Controller) updateFooStatus(foo *groupkindv1alpha1.Foo, deployment *appsv1.Deployment, hpa *autoscalingv2.HorizontalPodAutoscaler, service *corev1.Service, ingress *v1.Ingress) error {
+	// NEVER modify objects from the store. It's a read-only, local cache.
+	// You can use DeepCopy() to make a deep copy of original object and modify this copy
+	// Or create a copy manually for better performance
+	fooCopy := foo.DeepCopy()
+	fooCopy.Status.Replicas = deployment.Status.Replicas
+	fooCopy.Status.AvailableReplicas = deployment.Status.AvailableReplicas
+	fooCopy.Status.ReadyReplicas = deployment.Status.ReadyReplicas
+	fooCopy.Status.UpdatedReplicas = deployment.Status.UpdatedReplicas
+	fooCopy.Status.Selector = labels.SelectorFromSet(podLabels(foo)).String()
+	fooCopy.Status.ServiceClusterIP = ""
+	if service != nil {
+		fooCopy.Status.ServiceClusterIP = service.Spec.ClusterIP
+	}
+	fooCopy.Status.IngressAddresses = nil
+	if ingress != nil {
+		fooCopy.Status.IngressAddresses = ingressAddresses(ingress)
+	}
+	setSyncedConditions(&fooCopy.Status, foo.Generation, deployment, service, ingress)
+	setAutoscalingStatus(&fooCopy.Status, foo.Generation, hpa)
+	renames, err := c.pendingRenames(foo)
+	if err != nil {
+		return err
+	}
+	setRenameConditions(&fooCopy.Status, foo.Generation, renames)
+
+	if equality.Semantic.DeepEqual(foo.Status, fooCopy.Status) {
+		return nil
+	}
+	_, err = c.groupkindClientset.GroupkindV1alpha1().Foos(foo.Namespace).UpdateStatus(context.TODO(), fooCopy, metav1.UpdateOptions{})
+	return err
+}
+
+// updateFooStatusFailure records a failed sync in the conditions of the App
+// resource. It is best effort: the sync error is what gets the App requeued.
+func (c *Controller) updateFooStatusFailure(foo *groupkindv1alpha1.Foo, reason, message string) {
+	fooCopy := foo.DeepCopy()
+	setFailedConditions(&fooCopy.Status, foo.Generation, reason, message)
+
+	if equality.Semantic.DeepEqual(foo.Status, fooCopy.Status) {
+		return
+	}
+	_, err := c.groupkindClientset.GroupkindV1alpha1().Foos(foo.Namespace).UpdateStatus(context.TODO(), fooCopy, metav1.UpdateOptions{})
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to update status of app '%s/%s': %s", foo.Namespace, foo.Name, err.Error()))
+	}
+}
+
+// ingressAddresses returns the IPs and hostnames published in the load
+// balancer status of an Ingress.
+func ingressAddresses(ingress *v1.Ingress) []string {
+	var addresses []string
+	for _, lb := range ingress.Status.LoadBalancer.Ingress {
+		if lb.IP != "" {
+			addresses = append(addresses, lb.IP)
+		}
+		if lb.Hostname != "" {
+			addresses = append(addresses, lb.Hostname)
+		}
+	}
+	return addresses
 }
 
 // enqueueApp takes a App resource and converts it into a namespace/name
@@ -284,24 +681,76 @@ func (c *Controller) enqueueApp(obj interface{}) {
 	c.workqueue.Add(key)
 }
 
+// handleObject will take any resource implementing metav1.Object and attempt
+// to find the App resource that 'owns' it. It does this by looking at the
+// objects metadata.ownerReferences field for an appropriate OwnerReference.
+// It then enqueues that App resource to be processed. If the object does not
+// have an appropriate OwnerReference, it will simply be skipped.
+func (c *Controller) handleObject(obj interface{}) {
+	var object metav1.Object
+	var ok bool
+	if object, ok = obj.(metav1.Object); !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("error decoding object, invalid type"))
+			return
+		}
+		object, ok = tombstone.Obj.(metav1.Object)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("error decoding object tombstone, invalid type"))
+			return
+		}
+		klog.V(4).Infof("Recovered deleted object '%s' from tombstone", object.GetName())
+	}
+	klog.V(4).Infof("Processing object: %s", object.GetName())
+	ownerRef := metav1.GetControllerOf(object)
+	if ownerRef == nil {
+		return
+	}
+	// If this object is not owned by a resource of our API group, we should
+	// not do anything more with it.
+	gv, err := schema.ParseGroupVersion(ownerRef.APIVersion)
+	if err != nil || gv.Group != groupkindv1alpha1.SchemeGroupVersion.Group {
+		return
+	}
+
+	foo, err := c.foosLister.Foos(object.GetNamespace()).Get(ownerRef.Name)
+	if err != nil {
+		klog.V(4).Infof("ignoring orphaned object '%s/%s' of app '%s'", object.GetNamespace(), object.GetName(), ownerRef.Name)
+		return
+	}
+	if foo.UID != ownerRef.UID {
+		klog.V(4).Infof("ignoring object '%s/%s' owned by a previous app '%s'", object.GetNamespace(), object.GetName(), ownerRef.Name)
+		return
+	}
+
+	c.enqueueApp(foo)
+}
+
+// withDefaults returns a copy of the App with the defaults of its API
+// filled in.
+func withDefaults(foo *groupkindv1alpha1.Foo) *groupkindv1alpha1.Foo {
+	foo = foo.DeepCopy()
+	groupkindv1alpha1.SetObjectDefaults_Foo(foo)
+	return foo
+}
+
 // newDeployment creates a new Deployment for a App resource. It also sets
 // the appropriate OwnerReferences on the resource so handleObject can discover
 // the App resource that 'owns' it.
 func newDeployment(foo *groupkindv1alpha1.Foo) *appsv1.Deployment {
-	labels := map[string]string{
-		"foo":        "kindgroup",
-		"controller": foo.Name,
-	}
+	labels := podLabels(foo)
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      foo.Spec.Deployment.Name,
 			Namespace: foo.Namespace,
+			Labels:    childLabels(foo),
 			OwnerReferences: []metav1.OwnerReference{
-				*metav1.NewControllerRef(foo, groupkindv1alpha1.SchemeGroupVersion.WithKind("App")),
+				*metav1.NewControllerRef(foo, fooGVK),
 			},
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: &foo.Spec.Deployment.Replicas,
+			Replicas: deploymentReplicas(foo),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labels,
 			},
@@ -310,78 +759,275 @@ func newDeployment(foo *groupkindv1alpha1.Foo) *appsv1.Deployment {
 					Labels: labels,
 				},
 				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
-						{
-							Name:  foo.Spec.Deployment.Name,
-							Image: foo.Spec.Deployment.Image,
-						},
-					},
+					Containers:       []corev1.Container{newContainer(foo)},
+					ImagePullSecrets: foo.Spec.Deployment.ImagePullSecrets,
 				},
 			},
 		},
 	}
 }
 
-func newService(foo *groupkindv1alpha1.Foo) *corev1.Service {
-	labels := map[string]string{
-		"app":        "app-deployment",
+// deploymentReplicas is the number of pods of the App's Deployment, or nil
+// while a HorizontalPodAutoscaler scales it, so that the controller doesn't
+// undo its changes.
+func deploymentReplicas(foo *groupkindv1alpha1.Foo) *int32 {
+	if autoscalingEnabled(foo) {
+		return nil
+	}
+	return foo.Spec.Deployment.Replicas
+}
+
+// newContainer renders the container of the App's Deployment. Fields the API
+// server would default are filled in the same way, so that the rendered
+// container compares equal to the stored one.
+func newContainer(foo *groupkindv1alpha1.Foo) corev1.Container {
+	spec := foo.Spec.Deployment
+	return corev1.Container{
+		Name:            spec.Name,
+		Image:           spec.Image,
+		Command:         spec.Command,
+		Args:            spec.Args,
+		Env:             defaultEnv(spec.Env),
+		Ports:           defaultContainerPorts(spec.Ports),
+		Resources:       defaultResources(spec.Resources),
+		LivenessProbe:   defaultProbe(spec.LivenessProbe),
+		ReadinessProbe:  defaultProbe(spec.ReadinessProbe),
+		StartupProbe:    defaultProbe(spec.StartupProbe),
+		ImagePullPolicy: spec.ImagePullPolicy,
+	}
+}
+
+// podLabels are the labels of the pods of a App. They are used both as the
+// Deployment selector and as the Service selector.
+func podLabels(foo *groupkindv1alpha1.Foo) map[string]string {
+	return map[string]string{
+		"foo":        "kindgroup",
 		"controller": foo.Name,
 	}
+}
 
-	return &corev1.Service{
+// childLabels are the labels of the Deployment, Service and Ingress of a App:
+// the labels of the App itself, so that a controller watching only the Apps
+// matching a label selector sees their children too.
+func childLabels(foo *groupkindv1alpha1.Foo) map[string]string {
+	labels := make(map[string]string, len(foo.Labels))
+	for k, v := range foo.Labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// newHorizontalPodAutoscaler creates the HorizontalPodAutoscaler scaling the
+// Deployment of a App. It is named after the Deployment.
+func newHorizontalPodAutoscaler(foo *groupkindv1alpha1.Foo) *autoscalingv2.HorizontalPodAutoscaler {
+	spec := foo.Spec.Autoscaling
+	return &autoscalingv2.HorizontalPodAutoscaler{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      foo.Spec.Deployment.Name,
 			Namespace: foo.Namespace,
+			Labels:    childLabels(foo),
 			OwnerReferences: []metav1.OwnerReference{
-				*metav1.NewControllerRef(foo, groupkindv1alpha1.SchemeGroupVersion.WithKind("App")),
+				*metav1.NewControllerRef(foo, fooGVK),
+			},
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: appsv1.SchemeGroupVersion.String(),
+				Kind:       "Deployment",
+				Name:       foo.Spec.Deployment.Name,
 			},
+			MinReplicas: spec.MinReplicas,
+			MaxReplicas: spec.MaxReplicas,
+			Metrics:     newMetrics(spec),
 		},
-		Spec: corev1.ServiceSpec{
-			Selector: labels,
-			Ports: []corev1.ServicePort{
-				{
-					Protocol:   corev1.ProtocolTCP,
-					Port:       80,
-					TargetPort: intstr.IntOrString{IntVal: 80},
+	}
+}
+
+// newMetrics renders the targets of the App's HorizontalPodAutoscaler: the
+// CPU and memory utilisation of the pods, then their custom metrics.
+func newMetrics(spec *groupkindv1alpha1.AutoscalingSpec) []autoscalingv2.MetricSpec {
+	var metrics []autoscalingv2.MetricSpec
+	if spec.TargetCPUUtilizationPercentage != nil {
+		metrics = append(metrics, resourceMetric(corev1.ResourceCPU, *spec.TargetCPUUtilizationPercentage))
+	}
+	if spec.TargetMemoryUtilizationPercentage != nil {
+		metrics = append(metrics, resourceMetric(corev1.ResourceMemory, *spec.TargetMemoryUtilizationPercentage))
+	}
+	for _, m := range spec.CustomMetrics {
+		averageValue := m.AverageValue.DeepCopy()
+		metrics = append(metrics, autoscalingv2.MetricSpec{
+			Type: autoscalingv2.PodsMetricSourceType,
+			Pods: &autoscalingv2.PodsMetricSource{
+				Metric: autoscalingv2.MetricIdentifier{
+					Name:     m.Name,
+					Selector: m.Selector.DeepCopy(),
+				},
+				Target: autoscalingv2.MetricTarget{
+					Type:         autoscalingv2.AverageValueMetricType,
+					AverageValue: &averageValue,
 				},
 			},
+		})
+	}
+	return metrics
+}
+
+// resourceMetric targets the average utilisation of a resource by the pods,
+// as a percentage of their request.
+func resourceMetric(name corev1.ResourceName, utilization int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: &utilization,
+			},
+		},
+	}
+}
+
+func newService(foo *groupkindv1alpha1.Foo) *corev1.Service {
+	spec := corev1.ServiceSpec{
+		Selector:        podLabels(foo),
+		Ports:           newServicePorts(foo),
+		Type:            corev1.ServiceTypeClusterIP,
+		SessionAffinity: corev1.ServiceAffinityNone,
+	}
+	switch foo.Spec.Service.Type {
+	case groupkindv1alpha1.ServiceTypeNodePort:
+		spec.Type = corev1.ServiceTypeNodePort
+	case groupkindv1alpha1.ServiceTypeLoadBalancer:
+		spec.Type = corev1.ServiceTypeLoadBalancer
+	case groupkindv1alpha1.ServiceTypeHeadless:
+		spec.ClusterIP = corev1.ClusterIPNone
+	}
+	if foo.Spec.Service.SessionAffinity != "" {
+		spec.SessionAffinity = foo.Spec.Service.SessionAffinity
+	}
+
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        serviceName(foo),
+			Namespace:   foo.Namespace,
+			Labels:      childLabels(foo),
+			Annotations: withManagedAnnotations(foo.Spec.Service.Annotations),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(foo, fooGVK),
+			},
 		},
+		Spec: spec,
 	}
 }
 
+// newServicePorts renders the ports of the App's Service, defaulting to a
+// single TCP port 80 when none are given.
+func newServicePorts(foo *groupkindv1alpha1.Foo) []corev1.ServicePort {
+	if len(foo.Spec.Service.Ports) == 0 {
+		return []corev1.ServicePort{
+			{
+				Protocol:   corev1.ProtocolTCP,
+				Port:       80,
+				TargetPort: intstr.FromInt(80),
+			},
+		}
+	}
+
+	ports := make([]corev1.ServicePort, 0, len(foo.Spec.Service.Ports))
+	for _, p := range foo.Spec.Service.Ports {
+		port := corev1.ServicePort{
+			Name:       p.Name,
+			Protocol:   p.Protocol,
+			Port:       p.Port,
+			TargetPort: p.TargetPort,
+			NodePort:   p.NodePort,
+		}
+		if port.Protocol == "" {
+			port.Protocol = corev1.ProtocolTCP
+		}
+		if port.TargetPort.Type == intstr.Int && port.TargetPort.IntVal == 0 {
+			port.TargetPort = intstr.FromInt(int(p.Port))
+		}
+		ports = append(ports, port)
+	}
+	return ports
+}
+
 func newIngress(foo *groupkindv1alpha1.Foo) *v1.Ingress {
-	pathType := v1.PathTypePrefix
 	return &v1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      foo.Spec.Deployment.Name,
-			Namespace: foo.Namespace,
+			Name:        ingressName(foo),
+			Namespace:   foo.Namespace,
+			Labels:      childLabels(foo),
+			Annotations: withManagedAnnotations(foo.Spec.Ingress.Annotations),
 			OwnerReferences: []metav1.OwnerReference{
-				*metav1.NewControllerRef(foo, groupkindv1alpha1.SchemeGroupVersion.WithKind("App")),
+				*metav1.NewControllerRef(foo, fooGVK),
 			},
 		},
 		Spec: v1.IngressSpec{
-			Rules: []v1.IngressRule{
-				{
-					IngressRuleValue: v1.IngressRuleValue{
-						HTTP: &v1.HTTPIngressRuleValue{
-							Paths: []v1.HTTPIngressPath{
-								{
-									Path:     "/",
-									PathType: &pathType,
-									Backend: v1.IngressBackend{
-										Service: &v1.IngressServiceBackend{
-											Name: foo.Spec.Service.Name,
-											Port: v1.ServiceBackendPort{
-												Number: 80,
-											},
-										},
-									},
-								},
-							},
-						},
+			IngressClassName: foo.Spec.Ingress.IngressClassName,
+			Rules:            newIngressRules(foo),
+			TLS:              foo.Spec.Ingress.TLS,
+		},
+	}
+}
+
+// newIngressRules renders the rules of the App's Ingress, defaulting to a
+// single rule routing "/" on any host to the first Service port.
+func newIngressRules(foo *groupkindv1alpha1.Foo) []v1.IngressRule {
+	rules := foo.Spec.Ingress.Rules
+	if len(rules) == 0 {
+		rules = []groupkindv1alpha1.IngressRule{{Paths: []groupkindv1alpha1.IngressPath{{}}}}
+	}
+
+	rendered := make([]v1.IngressRule, 0, len(rules))
+	for _, rule := range rules {
+		paths := make([]v1.HTTPIngressPath, 0, len(rule.Paths))
+		for _, p := range rule.Paths {
+			paths = append(paths, v1.HTTPIngressPath{
+				Path:     ingressPath(p),
+				PathType: ingressPathType(p),
+				Backend: v1.IngressBackend{
+					Service: &v1.IngressServiceBackend{
+						Name: serviceName(foo),
+						Port: ingressBackendPort(foo, p),
 					},
 				},
+			})
+		}
+		rendered = append(rendered, v1.IngressRule{
+			Host: rule.Host,
+			IngressRuleValue: v1.IngressRuleValue{
+				HTTP: &v1.HTTPIngressRuleValue{Paths: paths},
 			},
-		},
+		})
+	}
+	return rendered
+}
+
+func ingressPath(p groupkindv1alpha1.IngressPath) string {
+	if p.Path == "" {
+		return "/"
+	}
+	return p.Path
+}
+
+func ingressPathType(p groupkindv1alpha1.IngressPath) *v1.PathType {
+	pathType := v1.PathTypePrefix
+	if p.PathType != nil {
+		pathType = *p.PathType
+	}
+	return &pathType
+}
+
+// ingressBackendPort resolves the Service port of a path, by name or number,
+// falling back to the first Service port.
+func ingressBackendPort(foo *groupkindv1alpha1.Foo, p groupkindv1alpha1.IngressPath) v1.ServiceBackendPort {
+	if p.Port.Type == intstr.String && p.Port.StrVal != "" {
+		return v1.ServiceBackendPort{Name: p.Port.StrVal}
+	}
+	if p.Port.IntVal != 0 {
+		return v1.ServiceBackendPort{Number: p.Port.IntVal}
 	}
+	return v1.ServiceBackendPort{Number: newServicePorts(foo)[0].Port}
 }
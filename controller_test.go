@@ -0,0 +1,1273 @@
+package main
+
+import (
+	groupkindv1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	"controller-crd/pkg/generated/clientset/versioned/fake"
+	informers "controller-crd/pkg/generated/informers/externalversions"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubeinformers "k8s.io/client-go/informers"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+var (
+	noResyncPeriodFunc = func() time.Duration { return 0 }
+)
+
+type fixture struct {
+	t *testing.T
+
+	client     *fake.Clientset
+	kubeclient *k8sfake.Clientset
+	recorder   *record.FakeRecorder
+	// Objects to put in the store.
+	fooLister        []*groupkindv1alpha1.Foo
+	deploymentLister []*appsv1.Deployment
+	serviceLister    []*corev1.Service
+	ingressLister    []*v1.Ingress
+	hpaLister        []*autoscalingv2.HorizontalPodAutoscaler
+	// Actions expected to happen on the client.
+	kubeactions []core.Action
+	actions     []core.Action
+	// Objects from here preloaded into NewSimpleFake.
+	kubeobjects []runtime.Object
+	objects     []runtime.Object
+	// How the controller writes children, ReconcileModeUpdate by default.
+	reconcileMode ReconcileMode
+}
+
+func newFixture(t *testing.T) *fixture {
+	f := &fixture{}
+	f.t = t
+	f.recorder = record.NewFakeRecorder(100)
+	f.objects = []runtime.Object{}
+	f.kubeobjects = []runtime.Object{}
+	return f
+}
+
+// newFoo returns a App with its defaults filled in, as the controller sees
+// it.
+func newFoo(name string, replicas int32) *groupkindv1alpha1.Foo {
+	return withDefaults(&groupkindv1alpha1.Foo{
+		TypeMeta: metav1.TypeMeta{APIVersion: groupkindv1alpha1.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  metav1.NamespaceDefault,
+			UID:        types.UID(name + "-uid"),
+			Generation: 1,
+		},
+		Spec: groupkindv1alpha1.FooSpec{
+			Deployment: groupkindv1alpha1.DeploymentSpec{
+				Name:     fmt.Sprintf("%s-deployment", name),
+				Image:    "nginx:1.23",
+				Replicas: &replicas,
+			},
+		},
+	})
+}
+
+// withAutoscaling has foo's Deployment scaled by a HorizontalPodAutoscaler
+// on the CPU usage of its pods.
+func withAutoscaling(foo *groupkindv1alpha1.Foo, minReplicas, maxReplicas int32) *groupkindv1alpha1.Foo {
+	foo.Spec.Autoscaling = &groupkindv1alpha1.AutoscalingSpec{
+		MinReplicas: &minReplicas,
+		MaxReplicas: maxReplicas,
+	}
+	groupkindv1alpha1.SetObjectDefaults_Foo(foo)
+	return foo
+}
+
+// withServiceAndIngress gives foo a Service and an Ingress routing to it,
+// both named after its Deployment.
+func withServiceAndIngress(foo *groupkindv1alpha1.Foo) *groupkindv1alpha1.Foo {
+	foo.Spec.Service = &groupkindv1alpha1.ServiceSpec{Name: foo.Spec.Deployment.Name}
+	foo.Spec.Ingress = &groupkindv1alpha1.IngressSpec{Name: foo.Spec.Deployment.Name}
+	groupkindv1alpha1.SetObjectDefaults_Foo(foo)
+	return foo
+}
+
+func (f *fixture) newController() (*Controller, informers.SharedInformerFactory, kubeinformers.SharedInformerFactory) {
+	f.client = fake.NewSimpleClientset(f.objects...)
+	f.kubeclient = k8sfake.NewSimpleClientset(f.kubeobjects...)
+
+	i := informers.NewSharedInformerFactory(f.client, noResyncPeriodFunc())
+	k8sI := kubeinformers.NewSharedInformerFactory(f.kubeclient, noResyncPeriodFunc())
+
+	c := NewController(f.kubeclient, f.client, []Informers{{
+		Namespace:                metav1.NamespaceAll,
+		Deployments:              k8sI.Apps().V1().Deployments(),
+		Services:                 k8sI.Core().V1().Services(),
+		Ingresses:                k8sI.Networking().V1().Ingresses(),
+		Foos:                     i.Groupkind().V1alpha1().Foos(),
+		HorizontalPodAutoscalers: k8sI.Autoscaling().V2().HorizontalPodAutoscalers(),
+	}}, ControllerOptions{ReconcileMode: f.reconcileMode})
+
+	c.recorder = f.recorder
+
+	for _, foo := range f.fooLister {
+		i.Groupkind().V1alpha1().Foos().Informer().GetIndexer().Add(foo)
+	}
+	for _, d := range f.deploymentLister {
+		k8sI.Apps().V1().Deployments().Informer().GetIndexer().Add(d)
+	}
+	for _, s := range f.serviceLister {
+		k8sI.Core().V1().Services().Informer().GetIndexer().Add(s)
+	}
+	for _, ing := range f.ingressLister {
+		k8sI.Networking().V1().Ingresses().Informer().GetIndexer().Add(ing)
+	}
+	for _, hpa := range f.hpaLister {
+		k8sI.Autoscaling().V2().HorizontalPodAutoscalers().Informer().GetIndexer().Add(hpa)
+	}
+
+	return c, i, k8sI
+}
+
+func (f *fixture) run(fooName string) {
+	f.runController(fooName, true, false)
+}
+
+func (f *fixture) runExpectError(fooName string) {
+	f.runController(fooName, true, true)
+}
+
+func (f *fixture) runController(fooName string, startInformers bool, expectError bool) {
+	c, i, k8sI := f.newController()
+	if startInformers {
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		i.Start(stopCh)
+		k8sI.Start(stopCh)
+	}
+
+	err := c.syncHandler(fooName)
+	if !expectError && err != nil {
+		f.t.Errorf("error syncing foo: %v", err)
+	} else if expectError && err == nil {
+		f.t.Error("expected error syncing foo, got nil")
+	}
+
+	f.checkActions()
+}
+
+// events returns the events recorded since the last call.
+func (f *fixture) events() []string {
+	var events []string
+	for {
+		select {
+		case event := <-f.recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+// checkActions verifies that the clients received exactly the expected
+// actions, in order.
+func (f *fixture) checkActions() {
+	actions := filterInformerActions(f.client.Actions())
+	for i, action := range actions {
+		if len(f.actions) < i+1 {
+			f.t.Errorf("%d unexpected actions: %+v", len(actions)-len(f.actions), actions[i:])
+			break
+		}
+
+		expectedAction := f.actions[i]
+		checkAction(expectedAction, action, f.t)
+	}
+
+	if len(f.actions) > len(actions) {
+		f.t.Errorf("%d additional expected actions:%+v", len(f.actions)-len(actions), f.actions[len(actions):])
+	}
+
+	k8sActions := filterInformerActions(f.kubeclient.Actions())
+	for i, action := range k8sActions {
+		if len(f.kubeactions) < i+1 {
+			f.t.Errorf("%d unexpected actions: %+v", len(k8sActions)-len(f.kubeactions), k8sActions[i:])
+			break
+		}
+
+		expectedAction := f.kubeactions[i]
+		checkAction(expectedAction, action, f.t)
+	}
+
+	if len(f.kubeactions) > len(k8sActions) {
+		f.t.Errorf("%d additional expected actions:%+v", len(f.kubeactions)-len(k8sActions), f.kubeactions[len(k8sActions):])
+	}
+}
+
+// checkAction verifies that expected and actual actions are equal and both have
+// same attached resources
+func checkAction(expected, actual core.Action, t *testing.T) {
+	if !(expected.Matches(actual.GetVerb(), actual.GetResource().Resource) && actual.GetSubresource() == expected.GetSubresource()) {
+		t.Errorf("Expected\n\t%#v\ngot\n\t%#v", expected, actual)
+		return
+	}
+
+	if reflect.TypeOf(actual) != reflect.TypeOf(expected) {
+		t.Errorf("Action has wrong type. Expected: %t. Got: %t", expected, actual)
+		return
+	}
+
+	switch a := actual.(type) {
+	case core.CreateActionImpl:
+		e, _ := expected.(core.CreateActionImpl)
+		expObject := withoutTransitionTimes(e.GetObject())
+		object := withoutTransitionTimes(a.GetObject())
+
+		if !reflect.DeepEqual(expObject, object) {
+			t.Errorf("Action %s %s has wrong object\nDiff:\n %s",
+				a.GetVerb(), a.GetResource().Resource, diff.ObjectGoPrintSideBySide(expObject, object))
+		}
+	case core.UpdateActionImpl:
+		e, _ := expected.(core.UpdateActionImpl)
+		expObject := withoutTransitionTimes(e.GetObject())
+		object := withoutTransitionTimes(a.GetObject())
+
+		if !reflect.DeepEqual(expObject, object) {
+			t.Errorf("Action %s %s has wrong object\nDiff:\n %s",
+				a.GetVerb(), a.GetResource().Resource, diff.ObjectGoPrintSideBySide(expObject, object))
+		}
+	case core.PatchActionImpl:
+		e, _ := expected.(core.PatchActionImpl)
+		var expPatch, patch interface{}
+		if err := json.Unmarshal(e.GetPatch(), &expPatch); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(a.GetPatch(), &patch); err != nil {
+			t.Fatal(err)
+		}
+
+		if e.GetName() != a.GetName() || e.GetPatchType() != a.GetPatchType() || !reflect.DeepEqual(expPatch, patch) {
+			t.Errorf("Action %s %s has wrong patch\nExpected:\n %s %s %s\nGot:\n %s %s %s",
+				a.GetVerb(), a.GetResource().Resource, e.GetName(), e.GetPatchType(), e.GetPatch(), a.GetName(), a.GetPatchType(), a.GetPatch())
+		}
+	case core.DeleteActionImpl:
+		e, _ := expected.(core.DeleteActionImpl)
+
+		if e.GetName() != a.GetName() || e.GetNamespace() != a.GetNamespace() {
+			t.Errorf("Action %s %s deleted %s/%s, expected %s/%s",
+				a.GetVerb(), a.GetResource().Resource, a.GetNamespace(), a.GetName(), e.GetNamespace(), e.GetName())
+		}
+	default:
+		t.Errorf("Uncaptured Action %s %s, you should explicitly add a case to capture it",
+			actual.GetVerb(), actual.GetResource().Resource)
+	}
+}
+
+// withoutTransitionTimes clears the transition times of the conditions of a
+// App, which are taken from the clock when the conditions are set.
+func withoutTransitionTimes(obj runtime.Object) runtime.Object {
+	foo, ok := obj.(*groupkindv1alpha1.Foo)
+	if !ok {
+		return obj
+	}
+	foo = foo.DeepCopy()
+	for i := range foo.Status.Conditions {
+		foo.Status.Conditions[i].LastTransitionTime = metav1.Time{}
+	}
+	return foo
+}
+
+// filterInformerActions filters list and watch actions for testing resources.
+// Since list and watch don't change resource state we can filter it to lower
+// nose level in our tests.
+func filterInformerActions(actions []core.Action) []core.Action {
+	ret := []core.Action{}
+	for _, action := range actions {
+		if len(action.GetNamespace()) == 0 &&
+			(action.Matches("list", "foos") ||
+				action.Matches("watch", "foos") ||
+				action.Matches("list", "deployments") ||
+				action.Matches("watch", "deployments") ||
+				action.Matches("list", "services") ||
+				action.Matches("watch", "services") ||
+				action.Matches("list", "ingresses") ||
+				action.Matches("watch", "ingresses") ||
+				action.Matches("list", "horizontalpodautoscalers") ||
+				action.Matches("watch", "horizontalpodautoscalers")) {
+			continue
+		}
+		ret = append(ret, action)
+	}
+
+	return ret
+}
+
+var (
+	deploymentsResource = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	servicesResource    = schema.GroupVersionResource{Version: "v1", Resource: "services"}
+	ingressesResource   = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+	hpasResource        = schema.GroupVersionResource{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"}
+	foosResource        = schema.GroupVersionResource{Group: groupkindv1alpha1.SchemeGroupVersion.Group, Version: groupkindv1alpha1.SchemeGroupVersion.Version, Resource: "foos"}
+)
+
+func (f *fixture) expectCreateDeploymentAction(d *appsv1.Deployment) {
+	f.kubeactions = append(f.kubeactions, core.NewCreateAction(deploymentsResource, d.Namespace, d))
+}
+
+func (f *fixture) expectUpdateDeploymentAction(d *appsv1.Deployment) {
+	f.kubeactions = append(f.kubeactions, core.NewUpdateAction(deploymentsResource, d.Namespace, d))
+}
+
+func (f *fixture) expectDeleteDeploymentAction(d *appsv1.Deployment) {
+	f.kubeactions = append(f.kubeactions, core.NewDeleteAction(deploymentsResource, d.Namespace, d.Name))
+}
+
+// expectApplyDeploymentAction expects d to be server-side applied.
+func (f *fixture) expectApplyDeploymentAction(d *appsv1.Deployment) {
+	patch, err := json.Marshal(deploymentApplyConfiguration(d))
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.kubeactions = append(f.kubeactions, core.NewPatchAction(deploymentsResource, d.Namespace, d.Name, types.ApplyPatchType, patch))
+}
+
+func (f *fixture) expectCreateServiceAction(s *corev1.Service) {
+	f.kubeactions = append(f.kubeactions, core.NewCreateAction(servicesResource, s.Namespace, s))
+}
+
+func (f *fixture) expectUpdateServiceAction(s *corev1.Service) {
+	f.kubeactions = append(f.kubeactions, core.NewUpdateAction(servicesResource, s.Namespace, s))
+}
+
+func (f *fixture) expectDeleteServiceAction(s *corev1.Service) {
+	f.kubeactions = append(f.kubeactions, core.NewDeleteAction(servicesResource, s.Namespace, s.Name))
+}
+
+func (f *fixture) expectCreateIngressAction(ing *v1.Ingress) {
+	f.kubeactions = append(f.kubeactions, core.NewCreateAction(ingressesResource, ing.Namespace, ing))
+}
+
+func (f *fixture) expectUpdateIngressAction(ing *v1.Ingress) {
+	f.kubeactions = append(f.kubeactions, core.NewUpdateAction(ingressesResource, ing.Namespace, ing))
+}
+
+func (f *fixture) expectDeleteIngressAction(ing *v1.Ingress) {
+	f.kubeactions = append(f.kubeactions, core.NewDeleteAction(ingressesResource, ing.Namespace, ing.Name))
+}
+
+func (f *fixture) expectCreateHorizontalPodAutoscalerAction(hpa *autoscalingv2.HorizontalPodAutoscaler) {
+	f.kubeactions = append(f.kubeactions, core.NewCreateAction(hpasResource, hpa.Namespace, hpa))
+}
+
+func (f *fixture) expectDeleteHorizontalPodAutoscalerAction(hpa *autoscalingv2.HorizontalPodAutoscaler) {
+	f.kubeactions = append(f.kubeactions, core.NewDeleteAction(hpasResource, hpa.Namespace, hpa.Name))
+}
+
+// expectPatchFooFinalizersAction expects the finalizers of foo, and nothing
+// else, to be set to finalizers.
+func (f *fixture) expectPatchFooFinalizersAction(foo *groupkindv1alpha1.Foo, finalizers []string) {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"finalizers":      finalizers,
+			"resourceVersion": foo.ResourceVersion,
+		},
+	})
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.actions = append(f.actions, core.NewPatchAction(foosResource, foo.Namespace, foo.Name, types.MergePatchType, patch))
+}
+
+func (f *fixture) expectUpdateFooStatusAction(foo *groupkindv1alpha1.Foo) {
+	action := core.NewUpdateSubresourceAction(foosResource, "status", foo.Namespace, foo)
+	f.actions = append(f.actions, action)
+}
+
+// syncedFoo returns foo with the status a successful sync computes from its
+// children.
+func syncedFoo(foo *groupkindv1alpha1.Foo, d *appsv1.Deployment, s *corev1.Service, ing *v1.Ingress) *groupkindv1alpha1.Foo {
+	foo = foo.DeepCopy()
+	foo.Status.Replicas = d.Status.Replicas
+	foo.Status.AvailableReplicas = d.Status.AvailableReplicas
+	foo.Status.ReadyReplicas = d.Status.ReadyReplicas
+	foo.Status.UpdatedReplicas = d.Status.UpdatedReplicas
+	foo.Status.Selector = labels.SelectorFromSet(d.Spec.Selector.MatchLabels).String()
+	if s != nil {
+		foo.Status.ServiceClusterIP = s.Spec.ClusterIP
+	}
+	if ing != nil {
+		foo.Status.IngressAddresses = ingressAddresses(ing)
+	}
+	setSyncedConditions(&foo.Status, foo.Generation, d, s, ing)
+	return foo
+}
+
+// rolledOut returns d with the status of a Deployment whose pods are all
+// available.
+func rolledOut(d *appsv1.Deployment) *appsv1.Deployment {
+	d = d.DeepCopy()
+	d.Status.Replicas = *d.Spec.Replicas
+	d.Status.AvailableReplicas = *d.Spec.Replicas
+	d.Status.ReadyReplicas = *d.Spec.Replicas
+	d.Status.UpdatedReplicas = *d.Spec.Replicas
+	return d
+}
+
+// autoscaledDeployment returns the Deployment of foo scaled to replicas by
+// its HorizontalPodAutoscaler, with all its pods available.
+func autoscaledDeployment(foo *groupkindv1alpha1.Foo, replicas int32) *appsv1.Deployment {
+	d := newDeployment(foo)
+	d.Spec.Replicas = &replicas
+	return rolledOut(d)
+}
+
+func deleting(foo *groupkindv1alpha1.Foo) *groupkindv1alpha1.Foo {
+	now := metav1.Now()
+	foo.DeletionTimestamp = &now
+	foo.Spec.Teardown = &groupkindv1alpha1.TeardownSpec{}
+	foo.Finalizers = []string{groupkindv1alpha1.TeardownFinalizer}
+	return foo
+}
+
+func getKey(foo *groupkindv1alpha1.Foo, t *testing.T) string {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(foo)
+	if err != nil {
+		t.Errorf("Unexpected error getting key for foo %v: %v", foo.Name, err)
+		return ""
+	}
+	return key
+}
+
+func TestCreatesDeployment(t *testing.T) {
+	f := newFixture(t)
+	foo := newFoo("test", 1)
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+
+	expDeployment := newDeployment(foo)
+	f.expectCreateDeploymentAction(expDeployment)
+	f.expectUpdateFooStatusAction(syncedFoo(foo, expDeployment, nil, nil))
+
+	f.run(getKey(foo, t))
+}
+
+func TestCreatesDeploymentWithDefaults(t *testing.T) {
+	f := newFixture(t)
+	foo := newFoo("test", 1)
+	foo.Labels = nil
+	foo.Spec.Deployment.Name = ""
+	foo.Spec.Deployment.Replicas = nil
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+
+	expDeployment := newDeployment(withDefaults(foo))
+	if expDeployment.Name != "test" || *expDeployment.Spec.Replicas != 1 || expDeployment.Labels[groupkindv1alpha1.NameLabel] != "test" {
+		t.Errorf("expected a deployment named and labelled after the app with 1 replica, got %#v", expDeployment.ObjectMeta)
+	}
+	f.expectCreateDeploymentAction(expDeployment)
+	f.expectUpdateFooStatusAction(syncedFoo(withDefaults(foo), expDeployment, nil, nil))
+
+	f.run(getKey(foo, t))
+}
+
+func TestCreatesServiceAndIngress(t *testing.T) {
+	f := newFixture(t)
+	foo := withServiceAndIngress(newFoo("test", 1))
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+
+	expDeployment := newDeployment(foo)
+	expService := newService(foo)
+	expIngress := newIngress(foo)
+	f.expectCreateDeploymentAction(expDeployment)
+	f.expectCreateServiceAction(expService)
+	f.expectCreateIngressAction(expIngress)
+	f.expectUpdateFooStatusAction(syncedFoo(foo, expDeployment, expService, expIngress))
+
+	f.run(getKey(foo, t))
+}
+
+func TestAddsFinalizer(t *testing.T) {
+	f := newFixture(t)
+	foo := newFoo("test", 1)
+	foo.Spec.Teardown = &groupkindv1alpha1.TeardownSpec{}
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+
+	expFoo := foo.DeepCopy()
+	expFoo.Finalizers = []string{groupkindv1alpha1.TeardownFinalizer}
+	expDeployment := newDeployment(foo)
+	f.expectPatchFooFinalizersAction(foo, expFoo.Finalizers)
+	f.expectCreateDeploymentAction(expDeployment)
+	f.expectUpdateFooStatusAction(syncedFoo(expFoo, expDeployment, nil, nil))
+
+	f.run(getKey(foo, t))
+}
+
+func TestDoNothing(t *testing.T) {
+	f := newFixture(t)
+	foo := withServiceAndIngress(newFoo("test", 1))
+	d := rolledOut(newDeployment(foo))
+	s := newService(foo)
+	ing := newIngress(foo)
+	foo = syncedFoo(foo, d, s, ing)
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.serviceLister = append(f.serviceLister, s)
+	f.ingressLister = append(f.ingressLister, ing)
+	f.kubeobjects = append(f.kubeobjects, d, s, ing)
+
+	f.run(getKey(foo, t))
+}
+
+func TestDoNothingWithHTTPProbe(t *testing.T) {
+	f := newFixture(t)
+	foo := newFoo("test", 1)
+	foo.Spec.Deployment.LivenessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Port: intstr.FromInt(80)}},
+	}
+	d := rolledOut(newDeployment(foo))
+	// The probe as the API server stores it, with its defaults.
+	d.Spec.Template.Spec.Containers[0].LivenessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{
+			Path:   "/",
+			Port:   intstr.FromInt(80),
+			Scheme: corev1.URISchemeHTTP,
+		}},
+		TimeoutSeconds:   1,
+		PeriodSeconds:    10,
+		SuccessThreshold: 1,
+		FailureThreshold: 3,
+	}
+	foo = syncedFoo(foo, d, nil, nil)
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.kubeobjects = append(f.kubeobjects, d)
+
+	f.run(getKey(foo, t))
+}
+
+func TestUpdateDeploymentOnReplicasChange(t *testing.T) {
+	f := newFixture(t)
+	foo := newFoo("test", 2)
+	d := rolledOut(newDeployment(newFoo("test", 1)))
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.kubeobjects = append(f.kubeobjects, d)
+
+	expDeployment := d.DeepCopy()
+	expDeployment.Spec.Replicas = foo.Spec.Deployment.Replicas
+	f.expectUpdateDeploymentAction(expDeployment)
+	f.expectUpdateFooStatusAction(syncedFoo(foo, expDeployment, nil, nil))
+
+	f.run(getKey(foo, t))
+}
+
+func TestUpdateDeploymentOnDrift(t *testing.T) {
+	f := newFixture(t)
+	foo := newFoo("test", 1)
+	d := rolledOut(newDeployment(foo))
+	d.Spec.Template.Spec.Containers[0].Image = "nginx:edited-by-hand"
+	// Fields the controller doesn't render are left as they are.
+	d.Spec.Template.Spec.NodeSelector = map[string]string{"disk": "ssd"}
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.kubeobjects = append(f.kubeobjects, d)
+
+	expDeployment := d.DeepCopy()
+	expDeployment.Spec.Template.Spec.Containers[0].Image = foo.Spec.Deployment.Image
+	f.expectUpdateDeploymentAction(expDeployment)
+	f.expectUpdateFooStatusAction(syncedFoo(foo, expDeployment, nil, nil))
+
+	f.run(getKey(foo, t))
+}
+
+func TestApplyDeploymentOnDrift(t *testing.T) {
+	f := newFixture(t)
+	f.reconcileMode = ReconcileModeApply
+	foo := newFoo("test", 1)
+	d := rolledOut(newDeployment(foo))
+	d.Spec.Template.Spec.Containers[0].Image = "nginx:edited-by-hand"
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.kubeobjects = append(f.kubeobjects, d)
+
+	f.expectApplyDeploymentAction(newDeployment(foo))
+	f.expectUpdateFooStatusAction(syncedFoo(foo, d, nil, nil))
+
+	f.run(getKey(foo, t))
+}
+
+func TestApplyLeavesReplicasToAutoscaler(t *testing.T) {
+	f := newFixture(t)
+	f.reconcileMode = ReconcileModeApply
+	foo := withAutoscaling(newFoo("test", 1), 2, 10)
+	d := autoscaledDeployment(foo, 5)
+	d.Spec.Template.Spec.Containers[0].Image = "nginx:edited-by-hand"
+	hpa := newHorizontalPodAutoscaler(foo)
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.hpaLister = append(f.hpaLister, hpa)
+	f.kubeobjects = append(f.kubeobjects, d, hpa)
+
+	// The applied deployment has no replicas, so the ones set by the
+	// autoscaler are kept.
+	f.expectApplyDeploymentAction(newDeployment(foo))
+	expFoo := syncedFoo(foo, d, nil, nil)
+	setAutoscalingStatus(&expFoo.Status, foo.Generation, hpa)
+	f.expectUpdateFooStatusAction(expFoo)
+
+	f.run(getKey(foo, t))
+}
+
+func TestUpdateServiceOnDrift(t *testing.T) {
+	f := newFixture(t)
+	foo := withServiceAndIngress(newFoo("test", 1))
+	foo.Spec.Ingress = nil
+	d := rolledOut(newDeployment(foo))
+	s := newService(foo)
+	s.Spec.Ports[0].Port = 8080
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.serviceLister = append(f.serviceLister, s)
+	f.kubeobjects = append(f.kubeobjects, d, s)
+
+	expService := newService(foo)
+	f.expectUpdateServiceAction(expService)
+	f.expectUpdateFooStatusAction(syncedFoo(foo, d, expService, nil))
+
+	f.run(getKey(foo, t))
+}
+
+func TestUpdateServiceRemovesAnnotations(t *testing.T) {
+	f := newFixture(t)
+	foo := withServiceAndIngress(newFoo("test", 1))
+	foo.Spec.Ingress = nil
+	foo.Spec.Service.Annotations = map[string]string{"example.com/scrape": "true", "example.com/port": "80"}
+	d := rolledOut(newDeployment(foo))
+	s := newService(foo)
+	s.Annotations["example.com/owner"] = "someone-else"
+	delete(foo.Spec.Service.Annotations, "example.com/port")
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.serviceLister = append(f.serviceLister, s)
+	f.kubeobjects = append(f.kubeobjects, d, s)
+
+	// The annotation removed from the spec is removed, the one set by
+	// someone else is kept.
+	expService := newService(foo)
+	expService.Annotations["example.com/owner"] = "someone-else"
+	f.expectUpdateServiceAction(expService)
+	f.expectUpdateFooStatusAction(syncedFoo(foo, d, expService, nil))
+
+	f.run(getKey(foo, t))
+}
+
+func TestUpdateIngressRemovesAnnotations(t *testing.T) {
+	f := newFixture(t)
+	foo := withServiceAndIngress(newFoo("test", 1))
+	foo.Spec.Ingress.Annotations = map[string]string{"example.com/rewrite": "/", "example.com/timeout": "30s"}
+	d := rolledOut(newDeployment(foo))
+	s := newService(foo)
+	ing := newIngress(foo)
+	ing.Annotations["example.com/owner"] = "someone-else"
+	foo.Spec.Ingress.Annotations = nil
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.serviceLister = append(f.serviceLister, s)
+	f.ingressLister = append(f.ingressLister, ing)
+	f.kubeobjects = append(f.kubeobjects, d, s, ing)
+
+	// Every annotation of the spec is gone, along with their record, and
+	// the one set by someone else is kept.
+	expIngress := newIngress(foo)
+	expIngress.Annotations = map[string]string{"example.com/owner": "someone-else"}
+	f.expectUpdateIngressAction(expIngress)
+	f.expectUpdateFooStatusAction(syncedFoo(foo, d, s, expIngress))
+
+	f.run(getKey(foo, t))
+}
+
+func TestNotControlledByUs(t *testing.T) {
+	f := newFixture(t)
+	foo := newFoo("test", 1)
+	d := newDeployment(foo)
+	d.ObjectMeta.OwnerReferences = []metav1.OwnerReference{}
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.kubeobjects = append(f.kubeobjects, d)
+
+	expFoo := foo.DeepCopy()
+	setFailedConditions(&expFoo.Status, foo.Generation, ErrResourceExists, fmt.Sprintf(MessageResourceExists, d.Name))
+	f.expectUpdateFooStatusAction(expFoo)
+
+	f.runExpectError(getKey(foo, t))
+}
+
+func TestDeletesDisabledService(t *testing.T) {
+	f := newFixture(t)
+	foo := withServiceAndIngress(newFoo("test", 1))
+	d := rolledOut(newDeployment(foo))
+	s := newService(foo)
+	foo.Spec.Service = nil
+	foo.Spec.Ingress = nil
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.serviceLister = append(f.serviceLister, s)
+	f.kubeobjects = append(f.kubeobjects, d, s)
+
+	f.expectDeleteServiceAction(s)
+	f.expectUpdateFooStatusAction(syncedFoo(foo, d, nil, nil))
+
+	f.run(getKey(foo, t))
+}
+
+func TestTeardownDeletesIngressFirst(t *testing.T) {
+	f := newFixture(t)
+	foo := withServiceAndIngress(newFoo("test", 1))
+	d := rolledOut(newDeployment(foo))
+	s := newService(foo)
+	ing := newIngress(foo)
+	foo = deleting(foo)
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.serviceLister = append(f.serviceLister, s)
+	f.ingressLister = append(f.ingressLister, ing)
+	f.kubeobjects = append(f.kubeobjects, d, s, ing)
+
+	f.expectDeleteIngressAction(ing)
+
+	f.run(getKey(foo, t))
+}
+
+func TestTeardownScalesDeploymentToZero(t *testing.T) {
+	f := newFixture(t)
+	foo := withServiceAndIngress(newFoo("test", 2))
+	d := rolledOut(newDeployment(foo))
+	s := newService(foo)
+	foo = deleting(foo)
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.serviceLister = append(f.serviceLister, s)
+	f.kubeobjects = append(f.kubeobjects, d, s)
+
+	expDeployment := d.DeepCopy()
+	var zero int32
+	expDeployment.Spec.Replicas = &zero
+	f.expectUpdateDeploymentAction(expDeployment)
+
+	f.run(getKey(foo, t))
+}
+
+func TestTeardownDeletesScaledDownDeployment(t *testing.T) {
+	f := newFixture(t)
+	foo := newFoo("test", 0)
+	d := rolledOut(newDeployment(foo))
+	foo = deleting(foo)
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.kubeobjects = append(f.kubeobjects, d)
+
+	f.expectDeleteDeploymentAction(d)
+
+	f.run(getKey(foo, t))
+}
+
+func TestTeardownRemovesFinalizer(t *testing.T) {
+	f := newFixture(t)
+	foo := deleting(newFoo("test", 1))
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+
+	f.expectPatchFooFinalizersAction(foo, nil)
+
+	f.run(getKey(foo, t))
+}
+
+func TestForceDeleteSkipsTeardown(t *testing.T) {
+	f := newFixture(t)
+	foo := newFoo("test", 1)
+	d := rolledOut(newDeployment(foo))
+	foo = deleting(foo)
+	foo.Annotations = map[string]string{groupkindv1alpha1.ForceDeleteAnnotation: "true"}
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.kubeobjects = append(f.kubeobjects, d)
+
+	f.expectPatchFooFinalizersAction(foo, nil)
+
+	f.run(getKey(foo, t))
+}
+
+func TestWaitForCacheSyncTimesOut(t *testing.T) {
+	f := newFixture(t)
+	c, _, _ := f.newController()
+	c.cacheSyncTimeout = 200 * time.Millisecond
+
+	// The informers are never started, so none of them syncs.
+	err := c.waitForCacheSync(make(chan struct{}))
+	if err == nil {
+		t.Fatal("expected error waiting for caches, got nil")
+	}
+	for _, name := range []string{"deployments", "services", "ingresses", "foos", "horizontalpodautoscalers"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("expected error to name informer %q, got %q", name, err.Error())
+		}
+	}
+}
+
+func TestWaitForCacheSync(t *testing.T) {
+	f := newFixture(t)
+	c, i, k8sI := f.newController()
+	c.cacheSyncTimeout = wait.ForeverTestTimeout
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	i.Start(stopCh)
+	k8sI.Start(stopCh)
+
+	if err := c.waitForCacheSync(stopCh); err != nil {
+		t.Errorf("unexpected error waiting for caches: %v", err)
+	}
+}
+
+func TestNamesChildrenAfterSpec(t *testing.T) {
+	f := newFixture(t)
+	foo := newFoo("test", 1)
+	foo.Spec.Service = &groupkindv1alpha1.ServiceSpec{Name: "web"}
+	foo.Spec.Ingress = &groupkindv1alpha1.IngressSpec{Name: "web-ingress"}
+	groupkindv1alpha1.SetObjectDefaults_Foo(foo)
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+
+	expDeployment := newDeployment(foo)
+	expService := newService(foo)
+	expIngress := newIngress(foo)
+	if expService.Name != "web" {
+		t.Errorf("expected service named %q, got %q", "web", expService.Name)
+	}
+	if expIngress.Name != "web-ingress" {
+		t.Errorf("expected ingress named %q, got %q", "web-ingress", expIngress.Name)
+	}
+	if backend := expIngress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name; backend != "web" {
+		t.Errorf("expected ingress to route to service %q, got %q", "web", backend)
+	}
+	f.expectCreateDeploymentAction(expDeployment)
+	f.expectCreateServiceAction(expService)
+	f.expectCreateIngressAction(expIngress)
+	f.expectUpdateFooStatusAction(syncedFoo(foo, expDeployment, expService, expIngress))
+
+	f.run(getKey(foo, t))
+}
+
+func TestRenameService(t *testing.T) {
+	f := newFixture(t)
+	foo := newFoo("test", 1)
+	foo.Spec.Service = &groupkindv1alpha1.ServiceSpec{Name: "old"}
+	d := rolledOut(newDeployment(foo))
+	s := newService(foo)
+	foo.Spec.Service.Name = "new"
+	groupkindv1alpha1.SetObjectDefaults_Foo(foo)
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.serviceLister = append(f.serviceLister, s)
+	f.kubeobjects = append(f.kubeobjects, d, s)
+
+	expService := newService(foo)
+	f.expectDeleteServiceAction(s)
+	f.expectCreateServiceAction(expService)
+	expFoo := syncedFoo(foo, d, expService, nil)
+	setRenameConditions(&expFoo.Status, foo.Generation, []string{`Service "old" is being renamed to "new"`})
+	f.expectUpdateFooStatusAction(expFoo)
+
+	f.run(getKey(foo, t))
+}
+
+func TestCreatesHorizontalPodAutoscaler(t *testing.T) {
+	f := newFixture(t)
+	foo := withAutoscaling(newFoo("test", 1), 2, 10)
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+
+	expDeployment := newDeployment(foo)
+	if expDeployment.Spec.Replicas != nil {
+		t.Errorf("expected the replicas of an autoscaled deployment to be left unset, got %d", *expDeployment.Spec.Replicas)
+	}
+	expHPA := newHorizontalPodAutoscaler(foo)
+	if target := expHPA.Spec.ScaleTargetRef; target.Kind != "Deployment" || target.Name != expDeployment.Name {
+		t.Errorf("expected the autoscaler to scale deployment %q, got %#v", expDeployment.Name, target)
+	}
+	if metrics := expHPA.Spec.Metrics; len(metrics) != 1 || metrics[0].Resource.Name != corev1.ResourceCPU || *metrics[0].Resource.Target.AverageUtilization != 80 {
+		t.Errorf("expected the autoscaler to default to 80%% CPU utilisation, got %#v", metrics)
+	}
+	f.expectCreateDeploymentAction(expDeployment)
+	f.expectCreateHorizontalPodAutoscalerAction(expHPA)
+	expFoo := syncedFoo(foo, expDeployment, nil, nil)
+	setAutoscalingStatus(&expFoo.Status, foo.Generation, expHPA)
+	f.expectUpdateFooStatusAction(expFoo)
+
+	f.run(getKey(foo, t))
+}
+
+func TestKeepsReplicasSetByAutoscaler(t *testing.T) {
+	f := newFixture(t)
+	foo := withAutoscaling(newFoo("test", 1), 2, 10)
+	d := autoscaledDeployment(foo, 5)
+	hpa := newHorizontalPodAutoscaler(foo)
+	foo = syncedFoo(foo, d, nil, nil)
+	setAutoscalingStatus(&foo.Status, foo.Generation, hpa)
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.hpaLister = append(f.hpaLister, hpa)
+	f.kubeobjects = append(f.kubeobjects, d, hpa)
+
+	f.run(getKey(foo, t))
+}
+
+func TestSurfacesAutoscalerStatus(t *testing.T) {
+	f := newFixture(t)
+	foo := withAutoscaling(newFoo("test", 1), 2, 10)
+	d := autoscaledDeployment(foo, 3)
+	hpa := newHorizontalPodAutoscaler(foo)
+	utilization := int32(90)
+	hpa.Status = autoscalingv2.HorizontalPodAutoscalerStatus{
+		CurrentReplicas: 3,
+		DesiredReplicas: 4,
+		CurrentMetrics: []autoscalingv2.MetricStatus{{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricStatus{
+				Name:    corev1.ResourceCPU,
+				Current: autoscalingv2.MetricValueStatus{AverageUtilization: &utilization},
+			},
+		}},
+		Conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{{
+			Type:    autoscalingv2.ScalingActive,
+			Status:  corev1.ConditionTrue,
+			Reason:  "ValidMetricFound",
+			Message: "the HPA was able to successfully calculate a replica count",
+		}},
+	}
+	foo = syncedFoo(foo, d, nil, nil)
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.hpaLister = append(f.hpaLister, hpa)
+	f.kubeobjects = append(f.kubeobjects, d, hpa)
+
+	expFoo := foo.DeepCopy()
+	expFoo.Status.Autoscaling = &groupkindv1alpha1.AutoscalingStatus{
+		CurrentReplicas:                 3,
+		DesiredReplicas:                 4,
+		CurrentCPUUtilizationPercentage: &utilization,
+	}
+	setCondition(&expFoo.Status, foo.Generation, groupkindv1alpha1.FooScalingActive, metav1.ConditionTrue,
+		"ValidMetricFound", "the HPA was able to successfully calculate a replica count")
+	f.expectUpdateFooStatusAction(expFoo)
+
+	f.run(getKey(foo, t))
+}
+
+func TestDisablingAutoscalingRestoresReplicas(t *testing.T) {
+	f := newFixture(t)
+	foo := withAutoscaling(newFoo("test", 1), 2, 10)
+	d := autoscaledDeployment(foo, 5)
+	hpa := newHorizontalPodAutoscaler(foo)
+	disabled := false
+	foo.Spec.Autoscaling.Enabled = &disabled
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.hpaLister = append(f.hpaLister, hpa)
+	f.kubeobjects = append(f.kubeobjects, d, hpa)
+
+	expDeployment := d.DeepCopy()
+	expDeployment.Spec.Replicas = foo.Spec.Deployment.Replicas
+	f.expectUpdateDeploymentAction(expDeployment)
+	f.expectDeleteHorizontalPodAutoscalerAction(hpa)
+	f.expectUpdateFooStatusAction(syncedFoo(foo, expDeployment, nil, nil))
+
+	f.run(getKey(foo, t))
+}
+
+func TestTeardownDeletesHorizontalPodAutoscaler(t *testing.T) {
+	f := newFixture(t)
+	foo := withAutoscaling(newFoo("test", 1), 2, 10)
+	d := autoscaledDeployment(foo, 5)
+	hpa := newHorizontalPodAutoscaler(foo)
+	foo = deleting(foo)
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.hpaLister = append(f.hpaLister, hpa)
+	f.kubeobjects = append(f.kubeobjects, d, hpa)
+
+	f.expectDeleteHorizontalPodAutoscalerAction(hpa)
+
+	f.run(getKey(foo, t))
+}
+
+func TestRenameDeployment(t *testing.T) {
+	f := newFixture(t)
+	foo := withAutoscaling(newFoo("test", 1), 2, 10)
+	foo.Spec.Deployment.Name = "old"
+	d := autoscaledDeployment(foo, 5)
+	hpa := newHorizontalPodAutoscaler(foo)
+	foo.Spec.Deployment.Name = "new"
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.hpaLister = append(f.hpaLister, hpa)
+	f.kubeobjects = append(f.kubeobjects, d, hpa)
+
+	// The old deployment is kept until the new one is available. The
+	// autoscaler is named after the deployment and replaced right away.
+	expDeployment := newDeployment(foo)
+	expHPA := newHorizontalPodAutoscaler(foo)
+	f.expectCreateDeploymentAction(expDeployment)
+	f.expectDeleteHorizontalPodAutoscalerAction(hpa)
+	f.expectCreateHorizontalPodAutoscalerAction(expHPA)
+	expFoo := syncedFoo(foo, expDeployment, nil, nil)
+	setAutoscalingStatus(&expFoo.Status, foo.Generation, expHPA)
+	setRenameConditions(&expFoo.Status, foo.Generation, []string{`Deployment "old" is being renamed to "new"`})
+	f.expectUpdateFooStatusAction(expFoo)
+
+	f.run(getKey(foo, t))
+}
+
+func TestRenameDeploymentDeletesOldOnceAvailable(t *testing.T) {
+	f := newFixture(t)
+	foo := withAutoscaling(newFoo("test", 1), 2, 10)
+	foo.Spec.Deployment.Name = "old"
+	old := autoscaledDeployment(foo, 5)
+	foo.Spec.Deployment.Name = "new"
+	d := autoscaledDeployment(foo, 2)
+	hpa := newHorizontalPodAutoscaler(foo)
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, old, d)
+	f.hpaLister = append(f.hpaLister, hpa)
+	f.kubeobjects = append(f.kubeobjects, old, d, hpa)
+
+	// The rename is reported until the deletion is observed.
+	f.expectDeleteDeploymentAction(old)
+	expFoo := syncedFoo(foo, d, nil, nil)
+	setAutoscalingStatus(&expFoo.Status, foo.Generation, hpa)
+	setRenameConditions(&expFoo.Status, foo.Generation, []string{`Deployment "old" is being renamed to "new"`})
+	f.expectUpdateFooStatusAction(expFoo)
+
+	f.run(getKey(foo, t))
+}
+
+func TestTeardownScalesRenamedDeploymentToZero(t *testing.T) {
+	f := newFixture(t)
+	foo := newFoo("test", 2)
+	foo.Spec.Deployment.Name = "old"
+	d := rolledOut(newDeployment(foo))
+	foo.Spec.Deployment.Name = "new"
+	foo = deleting(foo)
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.kubeobjects = append(f.kubeobjects, d)
+
+	expDeployment := d.DeepCopy()
+	var zero int32
+	expDeployment.Spec.Replicas = &zero
+	f.expectUpdateDeploymentAction(expDeployment)
+
+	f.run(getKey(foo, t))
+}
+
+func TestHostConflictWithOtherApp(t *testing.T) {
+	f := newFixture(t)
+	other := withRoute(newFoo("other", 1), "example.com", "/")
+	foo := withRoute(newFoo("test", 1), "example.com", "/")
+	other.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	foo.CreationTimestamp = metav1.Now()
+
+	f.fooLister = append(f.fooLister, other, foo)
+	f.objects = append(f.objects, other, foo)
+
+	msg := fmt.Sprintf(MessageHostConflict, "example.com", "/", other.Name)
+	f.expectCreateDeploymentAction(newDeployment(foo))
+	f.expectCreateServiceAction(newService(foo))
+	expFoo := foo.DeepCopy()
+	setFailedConditions(&expFoo.Status, foo.Generation, ErrHostConflict, msg)
+	f.expectUpdateFooStatusAction(expFoo)
+
+	f.runExpectError(getKey(foo, t))
+	if events := f.events(); len(events) != 1 || events[0] != corev1.EventTypeWarning+" "+ErrHostConflict+" "+msg {
+		t.Errorf("expected a %s event, got %q", ErrHostConflict, events)
+	}
+}
+
+func TestHostConflictWithUnmanagedIngress(t *testing.T) {
+	unowned := newIngress(withRoute(newFoo("legacy", 1), "example.com", "/"))
+	unowned.OwnerReferences = nil
+	// The Ingress of an App the controller doesn't list, e.g. one outside
+	// its label selector, is not checked through its App.
+	unlisted := newIngress(withRoute(newFoo("unlisted", 1), "example.com", "/"))
+
+	for name, ing := range map[string]*v1.Ingress{"unowned": unowned, "app not listed": unlisted} {
+		t.Run(name, func(t *testing.T) {
+			f := newFixture(t)
+			foo := withRoute(newFoo("test", 1), "example.com", "/")
+
+			f.fooLister = append(f.fooLister, foo)
+			f.objects = append(f.objects, foo)
+			f.ingressLister = append(f.ingressLister, ing)
+			f.kubeobjects = append(f.kubeobjects, ing)
+
+			msg := fmt.Sprintf(MessageHostConflictIngress, "example.com", "/", ing.Name)
+			f.expectCreateDeploymentAction(newDeployment(foo))
+			f.expectCreateServiceAction(newService(foo))
+			expFoo := foo.DeepCopy()
+			setFailedConditions(&expFoo.Status, foo.Generation, ErrHostConflict, msg)
+			f.expectUpdateFooStatusAction(expFoo)
+
+			f.runExpectError(getKey(foo, t))
+			if events := f.events(); len(events) != 1 || events[0] != corev1.EventTypeWarning+" "+ErrHostConflict+" "+msg {
+				t.Errorf("expected a %s event, got %q", ErrHostConflict, events)
+			}
+		})
+	}
+}
+
+func TestNoHostConflictWithOwnIngress(t *testing.T) {
+	f := newFixture(t)
+	foo := withRoute(newFoo("test", 1), "example.com", "/")
+	d := rolledOut(newDeployment(foo))
+	s := newService(foo)
+	ing := newIngress(foo)
+	foo = syncedFoo(foo, d, s, ing)
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.serviceLister = append(f.serviceLister, s)
+	f.ingressLister = append(f.ingressLister, ing)
+	f.kubeobjects = append(f.kubeobjects, d, s, ing)
+
+	f.run(getKey(foo, t))
+	if events := f.events(); len(events) != 1 || events[0] != corev1.EventTypeNormal+" "+SuccessSynced+" "+MessageResourceSynced {
+		t.Errorf("expected only a %s event, got %q", SuccessSynced, events)
+	}
+}
+
+func TestHandleObject(t *testing.T) {
+	foo := withAutoscaling(withServiceAndIngress(newFoo("test", 1)), 2, 10)
+	previous := newIngress(foo)
+	previous.OwnerReferences[0].UID = "previous-uid"
+	orphan := newService(foo)
+	orphan.OwnerReferences = nil
+	foreign := newDeployment(foo)
+	foreign.OwnerReferences[0].APIVersion = "apps/v1"
+	foreign.OwnerReferences[0].Kind = "ReplicaSet"
+
+	tests := []struct {
+		name    string
+		obj     interface{}
+		enqueue bool
+	}{
+		{name: "deployment", obj: newDeployment(foo), enqueue: true},
+		{name: "ingress", obj: newIngress(foo), enqueue: true},
+		{name: "horizontal pod autoscaler", obj: newHorizontalPodAutoscaler(foo), enqueue: true},
+		{
+			name:    "deleted service tombstone",
+			obj:     cache.DeletedFinalStateUnknown{Key: "default/" + serviceName(foo), Obj: newService(foo)},
+			enqueue: true,
+		},
+		{
+			name:    "deleted horizontal pod autoscaler tombstone",
+			obj:     cache.DeletedFinalStateUnknown{Key: "default/" + foo.Spec.Deployment.Name, Obj: newHorizontalPodAutoscaler(foo)},
+			enqueue: true,
+		},
+		{name: "tombstone of an unknown type", obj: cache.DeletedFinalStateUnknown{Key: "default/test", Obj: "test"}},
+		{name: "child of a previous app", obj: previous},
+		{name: "orphan", obj: orphan},
+		{name: "child of another API group", obj: foreign},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			f.fooLister = append(f.fooLister, foo)
+			c, _, _ := f.newController()
+			defer c.workqueue.ShutDown()
+
+			c.handleObject(tt.obj)
+			if !tt.enqueue {
+				if c.workqueue.Len() != 0 {
+					t.Errorf("expected nothing to be enqueued, got %d items", c.workqueue.Len())
+				}
+				return
+			}
+			if c.workqueue.Len() != 1 {
+				t.Fatalf("expected the owning app to be enqueued, got %d items", c.workqueue.Len())
+			}
+			key, _ := c.workqueue.Get()
+			if key != getKey(foo, t) {
+				t.Errorf("expected key %q to be enqueued, got %q", getKey(foo, t), key)
+			}
+		})
+	}
+}
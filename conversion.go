@@ -0,0 +1,116 @@
+package main
+
+import (
+	groupkindv1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	groupkindv1beta1 "controller-crd/pkg/apis/groupkind/v1beta1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+)
+
+// conversionWebhookPath is the path the conversion webhook of Apps is served
+// on.
+const conversionWebhookPath = "/convert"
+
+// conversionHandler converts the Apps of the ConversionReviews posted by the
+// API server. Every version is converted through the hub version, v1alpha1,
+// which is also the version Apps are stored in.
+func conversionHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+			http.Error(w, fmt.Sprintf("unsupported content type %q", contentType), http.StatusUnsupportedMediaType)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxAdmissionReviewBytes))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		review := apiextensionsv1.ConversionReview{}
+		if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+			http.Error(w, "failed to decode conversion review", http.StatusBadRequest)
+			return
+		}
+
+		response := &apiextensionsv1.ConversionResponse{
+			UID:    review.Request.UID,
+			Result: metav1.Status{Status: metav1.StatusSuccess},
+		}
+		for _, obj := range review.Request.Objects {
+			converted, err := convertFoo(obj, review.Request.DesiredAPIVersion)
+			if err != nil {
+				klog.V(4).Infof("Failed to convert app to %s: %s", review.Request.DesiredAPIVersion, err.Error())
+				response.ConvertedObjects = nil
+				response.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+				break
+			}
+			response.ConvertedObjects = append(response.ConvertedObjects, converted)
+		}
+		review.Request = nil
+		review.Response = response
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(review); err != nil {
+			klog.Errorf("Error writing conversion response: %s", err.Error())
+		}
+	})
+}
+
+// convertFoo converts an App to desiredAPIVersion.
+func convertFoo(obj runtime.RawExtension, desiredAPIVersion string) (runtime.RawExtension, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(obj.Raw, &typeMeta); err != nil {
+		return runtime.RawExtension{}, fmt.Errorf("failed to decode app: %s", err.Error())
+	}
+	if typeMeta.Kind != "Foo" {
+		return runtime.RawExtension{}, fmt.Errorf("unexpected kind %q", typeMeta.Kind)
+	}
+
+	hub := &groupkindv1alpha1.Foo{}
+	switch typeMeta.APIVersion {
+	case groupkindv1alpha1.SchemeGroupVersion.String():
+		if err := json.Unmarshal(obj.Raw, hub); err != nil {
+			return runtime.RawExtension{}, fmt.Errorf("failed to decode app: %s", err.Error())
+		}
+	case groupkindv1beta1.SchemeGroupVersion.String():
+		spoke := &groupkindv1beta1.Foo{}
+		if err := json.Unmarshal(obj.Raw, spoke); err != nil {
+			return runtime.RawExtension{}, fmt.Errorf("failed to decode app: %s", err.Error())
+		}
+		if err := spoke.ConvertTo(hub); err != nil {
+			return runtime.RawExtension{}, err
+		}
+	default:
+		return runtime.RawExtension{}, fmt.Errorf("unsupported version %q", typeMeta.APIVersion)
+	}
+
+	var converted interface{}
+	switch desiredAPIVersion {
+	case groupkindv1alpha1.SchemeGroupVersion.String():
+		hub.APIVersion = desiredAPIVersion
+		converted = hub
+	case groupkindv1beta1.SchemeGroupVersion.String():
+		spoke := &groupkindv1beta1.Foo{}
+		if err := spoke.ConvertFrom(hub); err != nil {
+			return runtime.RawExtension{}, err
+		}
+		converted = spoke
+	default:
+		return runtime.RawExtension{}, fmt.Errorf("unsupported version %q", desiredAPIVersion)
+	}
+	raw, err := json.Marshal(converted)
+	if err != nil {
+		return runtime.RawExtension{}, err
+	}
+	return runtime.RawExtension{Raw: raw}, nil
+}
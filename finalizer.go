@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	groupkindv1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ReasonTeardown is used as part of the Event 'reason' for every step of
+	// the ordered teardown of a App.
+	ReasonTeardown = "Teardown"
+	// ReasonTeardownComplete is used as part of the Event 'reason' when the
+	// teardown of a App finished and its finalizer was removed.
+	ReasonTeardownComplete = "TeardownComplete"
+	// ReasonTeardownForced is used as part of the Event 'reason' when the
+	// finalizer of a App is removed before its teardown finished.
+	ReasonTeardownForced = "TeardownForced"
+
+	// teardownPollInterval is how often a App being torn down is resynced
+	// while waiting on its children.
+	teardownPollInterval = 2 * time.Second
+)
+
+// syncFinalizer adds the teardown finalizer to a App that asks for a teardown
+// and removes it from a App that no longer does. It returns the App with its
+// finalizers and resource version as stored on the API server.
+func (c *Controller) syncFinalizer(foo *groupkindv1alpha1.Foo) (*groupkindv1alpha1.Foo, error) {
+	want := foo.Spec.Teardown != nil
+	if want == hasFinalizer(foo) {
+		return foo, nil
+	}
+
+	var finalizers []string
+	if want {
+		finalizers = append(append(finalizers, foo.Finalizers...), groupkindv1alpha1.TeardownFinalizer)
+	} else {
+		finalizers = withoutFinalizer(foo.Finalizers)
+	}
+	patched, err := c.patchFinalizers(foo, finalizers)
+	if err != nil {
+		return nil, err
+	}
+	fooCopy := foo.DeepCopy()
+	fooCopy.Finalizers = patched.Finalizers
+	fooCopy.ResourceVersion = patched.ResourceVersion
+	return fooCopy, nil
+}
+
+// patchFinalizers sets the finalizers of a App and nothing else: the App
+// handed to the sync functions carries the defaults of its API, which must
+// not be written back over the fields its users manage. The resource version
+// makes the patch fail on conflict like an update would.
+func (c *Controller) patchFinalizers(foo *groupkindv1alpha1.Foo, finalizers []string) (*groupkindv1alpha1.Foo, error) {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"finalizers":      finalizers,
+			"resourceVersion": foo.ResourceVersion,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.groupkindClientset.GroupkindV1alpha1().Foos(foo.Namespace).Patch(context.TODO(), foo.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+}
+
+// syncDeletion tears down the children of a App being deleted, ingress
+// first, then the horizontal pod autoscaler so that it doesn't scale the
+// deployments back up, then the deployments scaled to zero, then the rest,
+// and removes the finalizer once they are gone. A App without the finalizer
+// is left to the garbage collector.
+func (c *Controller) syncDeletion(key string, foo *groupkindv1alpha1.Foo) error {
+	if !hasFinalizer(foo) {
+		return nil
+	}
+
+	if foo.Annotations[groupkindv1alpha1.ForceDeleteAnnotation] == "true" {
+		c.recorder.Eventf(foo, corev1.EventTypeWarning, ReasonTeardownForced, "Teardown skipped by the %s annotation", groupkindv1alpha1.ForceDeleteAnnotation)
+		return c.removeFinalizer(foo)
+	}
+	timeout := teardownTimeout(foo)
+	if time.Since(foo.DeletionTimestamp.Time) > timeout {
+		c.recorder.Eventf(foo, corev1.EventTypeWarning, ReasonTeardownForced, "Teardown did not finish within %s", timeout)
+		return c.removeFinalizer(foo)
+	}
+
+	done, err := c.teardown(foo)
+	if err != nil {
+		return err
+	}
+	if !done {
+		klog.V(4).Infof("Teardown of app '%s' in progress", key)
+		c.workqueue.AddAfter(key, teardownPollInterval)
+		return nil
+	}
+
+	c.recorder.Event(foo, corev1.EventTypeNormal, ReasonTeardownComplete, "Children of the app were torn down")
+	return c.removeFinalizer(foo)
+}
+
+// teardown moves the children of a App one step closer to being deleted and
+// reports whether they are all gone. Children not controlled by the App are
+// never touched.
+func (c *Controller) teardown(foo *groupkindv1alpha1.Foo) (bool, error) {
+	ctx := context.TODO()
+
+	// Drain the ingress first so no new traffic reaches the pods.
+	ingresses, err := c.ownedIngresses(foo)
+	if err != nil {
+		return false, err
+	}
+	if len(ingresses) > 0 {
+		for _, ingress := range ingresses {
+			if ingress.DeletionTimestamp == nil {
+				c.recorder.Eventf(foo, corev1.EventTypeNormal, ReasonTeardown, "Deleting ingress %q", ingress.Name)
+			}
+		}
+		return false, c.deleteOwnedIngresses(foo, "")
+	}
+
+	// Stop autoscaling before scaling the deployment down.
+	hpas, err := c.ownedHorizontalPodAutoscalers(foo)
+	if err != nil {
+		return false, err
+	}
+	if len(hpas) > 0 {
+		for _, hpa := range hpas {
+			if hpa.DeletionTimestamp == nil {
+				c.recorder.Eventf(foo, corev1.EventTypeNormal, ReasonTeardown, "Deleting horizontal pod autoscaler %q", hpa.Name)
+			}
+		}
+		return false, c.deleteOwnedHorizontalPodAutoscalers(foo, "")
+	}
+
+	// Then scale the deployments to zero and wait for their pods to go away
+	// before deleting them. Deployments left behind by a rename are torn
+	// down along with the current one.
+	deployments, err := c.ownedDeployments(foo)
+	if err != nil {
+		return false, err
+	}
+	if len(deployments) > 0 {
+		for _, deployment := range deployments {
+			if err := c.teardownDeployment(ctx, foo, deployment); err != nil {
+				return false, err
+			}
+		}
+		return false, nil
+	}
+
+	// Finally delete the service.
+	services, err := c.ownedServices(foo)
+	if err != nil {
+		return false, err
+	}
+	if len(services) > 0 {
+		for _, service := range services {
+			if service.DeletionTimestamp == nil {
+				c.recorder.Eventf(foo, corev1.EventTypeNormal, ReasonTeardown, "Deleting service %q", service.Name)
+			}
+		}
+		return false, c.deleteOwnedServices(foo, "")
+	}
+
+	return true, nil
+}
+
+// teardownDeployment scales a Deployment of a App being deleted to zero, and
+// deletes it once its pods are gone.
+func (c *Controller) teardownDeployment(ctx context.Context, foo *groupkindv1alpha1.Foo, deployment *appsv1.Deployment) error {
+	if deployment.DeletionTimestamp != nil {
+		return nil
+	}
+	if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 0 {
+		c.recorder.Eventf(foo, corev1.EventTypeNormal, ReasonTeardown, "Scaling deployment %q to zero", deployment.Name)
+		deploymentCopy := deployment.DeepCopy()
+		var zero int32
+		deploymentCopy.Spec.Replicas = &zero
+		_, err := c.kubeclientset.AppsV1().Deployments(foo.Namespace).Update(ctx, deploymentCopy, metav1.UpdateOptions{})
+		if err == nil {
+			recordChildOperation("Deployment", operationUpdate)
+		}
+		return err
+	}
+	if deployment.Status.Replicas > 0 {
+		return nil
+	}
+	c.recorder.Eventf(foo, corev1.EventTypeNormal, ReasonTeardown, "Deleting deployment %q", deployment.Name)
+	err := c.kubeclientset.AppsV1().Deployments(foo.Namespace).Delete(ctx, deployment.Name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	recordChildOperation("Deployment", operationDelete)
+	return nil
+}
+
+func (c *Controller) removeFinalizer(foo *groupkindv1alpha1.Foo) error {
+	_, err := c.patchFinalizers(foo, withoutFinalizer(foo.Finalizers))
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove finalizer from app '%s/%s': %s", foo.Namespace, foo.Name, err.Error())
+	}
+	return nil
+}
+
+func hasFinalizer(foo *groupkindv1alpha1.Foo) bool {
+	for _, f := range foo.Finalizers {
+		if f == groupkindv1alpha1.TeardownFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+func withoutFinalizer(finalizers []string) []string {
+	var kept []string
+	for _, f := range finalizers {
+		if f != groupkindv1alpha1.TeardownFinalizer {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+func teardownTimeout(foo *groupkindv1alpha1.Foo) time.Duration {
+	seconds := int32(groupkindv1alpha1.DefaultTeardownTimeoutSeconds)
+	if foo.Spec.Teardown != nil && foo.Spec.Teardown.TimeoutSeconds != nil {
+		seconds = *foo.Spec.Teardown.TimeoutSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -25,10 +25,52 @@ CODEGEN_PKG=${CODEGEN_PKG:-$(cd "${SCRIPT_ROOT}"; ls -d -1 sh /vendor/k8s.io/cod
 # --output-base    because this script should also be able to run inside the vendor dir of
 #                  k8s.io/kubernetes. The output-base is needed for the generators to output into the vendor dir
 #                  instead of the $GOPATH directly. For normal projects this can be dropped.
-"${CODEGEN_PKG}/generate-groups.sh" "deepcopy,client,informer,lister" \
+"${CODEGEN_PKG}/generate-groups.sh" "deepcopy,informer,lister" \
   controller-crd/pkg/generated \
   controller-crd/pkg/apis \
-  groupkind:v1alpha1 \
+  groupkind:v1alpha1,v1beta1 \
+  --output-base "$(dirname "${BASH_SOURCE[0]}")/../../" \
+  --go-header-file "${SCRIPT_ROOT}"/hack/boilerplate.go.txt
+
+# generate-groups.sh installs defaulter-gen but only runs it for internal
+# types, so the defaulters of the SetDefaults_ functions are generated here.
+GOBIN="$(go env GOBIN)"
+"${GOBIN:-$(go env GOPATH)/bin}/defaulter-gen" \
+  --input-dirs controller-crd/pkg/apis/groupkind/v1alpha1 \
+  -O zz_generated.defaults \
+  --output-base "$(dirname "${BASH_SOURCE[0]}")/../../" \
+  --go-header-file "${SCRIPT_ROOT}"/hack/boilerplate.go.txt
+
+# The clientset is generated with typed Apply methods, which generate-groups.sh
+# can't ask client-gen for, so it is generated here along with the apply
+# configurations. Types of other APIs used in ours map to the apply
+# configurations client-go already has for them.
+(cd "${CODEGEN_PKG}" && GO111MODULE=on go install k8s.io/code-generator/cmd/applyconfiguration-gen)
+EXTERNAL_APPLYCONFIGURATIONS=(
+  k8s.io/apimachinery/pkg/apis/meta/v1.Condition:k8s.io/client-go/applyconfigurations/meta/v1
+  k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector:k8s.io/client-go/applyconfigurations/meta/v1
+  k8s.io/api/core/v1.EnvVar:k8s.io/client-go/applyconfigurations/core/v1
+  k8s.io/api/core/v1.ContainerPort:k8s.io/client-go/applyconfigurations/core/v1
+  k8s.io/api/core/v1.ResourceRequirements:k8s.io/client-go/applyconfigurations/core/v1
+  k8s.io/api/core/v1.Probe:k8s.io/client-go/applyconfigurations/core/v1
+  k8s.io/api/core/v1.LocalObjectReference:k8s.io/client-go/applyconfigurations/core/v1
+  k8s.io/api/networking/v1.IngressTLS:k8s.io/client-go/applyconfigurations/networking/v1
+)
+echo "Generating apply configurations for groupkind:v1alpha1,v1beta1 at controller-crd/pkg/generated/applyconfiguration"
+"${GOBIN:-$(go env GOPATH)/bin}/applyconfiguration-gen" \
+  --input-dirs controller-crd/pkg/apis/groupkind/v1alpha1,controller-crd/pkg/apis/groupkind/v1beta1 \
+  --external-applyconfigurations "$(IFS=,; echo "${EXTERNAL_APPLYCONFIGURATIONS[*]}")" \
+  --output-package controller-crd/pkg/generated/applyconfiguration \
+  --output-base "$(dirname "${BASH_SOURCE[0]}")/../../" \
+  --go-header-file "${SCRIPT_ROOT}"/hack/boilerplate.go.txt
+
+echo "Generating clientset for groupkind:v1alpha1,v1beta1 at controller-crd/pkg/generated/clientset"
+"${GOBIN:-$(go env GOPATH)/bin}/client-gen" \
+  --clientset-name versioned \
+  --input-base "" \
+  --input controller-crd/pkg/apis/groupkind/v1alpha1,controller-crd/pkg/apis/groupkind/v1beta1 \
+  --apply-configuration-package controller-crd/pkg/generated/applyconfiguration \
+  --output-package controller-crd/pkg/generated/clientset \
   --output-base "$(dirname "${BASH_SOURCE[0]}")/../../" \
   --go-header-file "${SCRIPT_ROOT}"/hack/boilerplate.go.txt
 
@@ -0,0 +1,39 @@
+#!/usr/bin/env bash
+
+# Copyright 2017 The Kubernetes Authors.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+SCRIPT_ROOT=$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)
+CRD_DIR=${CRD_DIR:-"${SCRIPT_ROOT}/config/crd"}
+CONTROLLER_TOOLS_VERSION=${CONTROLLER_TOOLS_VERSION:-v0.16.5}
+
+# controller-gen is installed at a pinned version rather than tracked in
+# go.mod, as it needs newer Kubernetes libraries than the controller.
+CONTROLLER_GEN="${SCRIPT_ROOT}/_output/bin/controller-gen"
+if [[ ! -x "${CONTROLLER_GEN}" ]] || ! "${CONTROLLER_GEN}" --version | grep -q "${CONTROLLER_TOOLS_VERSION}"; then
+  GOBIN="${SCRIPT_ROOT}/_output/bin" go install "sigs.k8s.io/controller-tools/cmd/controller-gen@${CONTROLLER_TOOLS_VERSION}"
+fi
+
+# controller-gen reads the kubebuilder markers in the API types and writes
+# one CustomResourceDefinition per resource, with the OpenAPI schema, the
+# subresources and the printer columns.
+cd "${SCRIPT_ROOT}"
+"${CONTROLLER_GEN}" \
+  crd:crdVersions=v1 \
+  paths=./pkg/apis/... \
+  output:crd:dir="${CRD_DIR}"
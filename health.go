@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/tools/leaderelection"
+)
+
+// healthCheck is a named check served on /healthz or /readyz.
+type healthCheck struct {
+	name  string
+	check func(r *http.Request) error
+}
+
+// healthChecker backs the /healthz and /readyz endpoints of the process.
+type healthChecker struct {
+	controller *Controller
+	// stallTimeout is how long the workers may go without dequeuing while
+	// the workqueue is not empty before the process is reported unhealthy.
+	stallTimeout time.Duration
+	// leaderElection is nil when leader election is disabled.
+	leaderElection *leaderelection.HealthzAdaptor
+	// leading is set to 1 once this replica runs the controller.
+	leading int32
+}
+
+func newHealthChecker(controller *Controller, stallTimeout time.Duration, leaderElection *leaderelection.HealthzAdaptor) *healthChecker {
+	return &healthChecker{
+		controller:     controller,
+		stallTimeout:   stallTimeout,
+		leaderElection: leaderElection,
+	}
+}
+
+// setLeading records that this replica runs the controller.
+func (h *healthChecker) setLeading() {
+	atomic.StoreInt32(&h.leading, 1)
+}
+
+func (h *healthChecker) isLeading() bool {
+	return atomic.LoadInt32(&h.leading) == 1
+}
+
+// install registers /healthz and /readyz on mux.
+func (h *healthChecker) install(mux *http.ServeMux) {
+	mux.Handle("/healthz", checksHandler(h.livenessChecks()))
+	mux.Handle("/readyz", checksHandler(h.readinessChecks()))
+}
+
+// livenessChecks fail when the process should be restarted: its workers are
+// stuck, or it holds a lease it failed to renew.
+func (h *healthChecker) livenessChecks() []healthCheck {
+	checks := []healthCheck{{
+		name: "workers",
+		check: func(*http.Request) error {
+			if !h.isLeading() {
+				return nil
+			}
+			return h.controller.checkWorkers(h.stallTimeout)
+		},
+	}}
+	if h.leaderElection != nil {
+		checks = append(checks, healthCheck{name: "leader-election", check: h.leaderElection.Check})
+	}
+	return checks
+}
+
+// readinessChecks fail until the replica running the controller has synced
+// its informer caches. A standby replica waiting for the lease is ready, so
+// that it doesn't block rollouts.
+func (h *healthChecker) readinessChecks() []healthCheck {
+	return []healthCheck{{
+		name: "informer-sync",
+		check: func(*http.Request) error {
+			if h.leaderElection != nil && !h.isLeading() {
+				return nil
+			}
+			if !h.isLeading() {
+				return fmt.Errorf("controller not started")
+			}
+			if !h.controller.cachesSynced() {
+				if unsynced := h.controller.unsyncedInformers(); len(unsynced) > 0 {
+					return fmt.Errorf("waiting on %s", strings.Join(unsynced, ", "))
+				}
+				return fmt.Errorf("workers not started")
+			}
+			return nil
+		},
+	}}
+}
+
+// checksHandler runs every check and answers 200 "ok" when they all pass, or
+// 500 with the failing checks listed.
+func checksHandler(checks []healthCheck) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var failed []string
+		for _, c := range checks {
+			if err := c.check(r); err != nil {
+				failed = append(failed, fmt.Sprintf("[-]%s failed: %s", c.name, err.Error()))
+			}
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if len(failed) > 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintln(w, strings.Join(failed, "\n"))
+			return
+		}
+		fmt.Fprint(w, "ok")
+	})
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	groupkindv1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	"fmt"
+
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+)
+
+const (
+	// ErrHostConflict is used as part of the Event 'reason' when a App fails
+	// to sync because another App already routes one of its hosts and paths.
+	ErrHostConflict = "ErrHostConflict"
+	// MessageHostConflict is the message used for Events when a App fails to
+	// sync because of a host conflict.
+	MessageHostConflict = "Host %q path %q is already routed by App %q"
+	// MessageHostConflictIngress is the message used for Events when a App
+	// fails to sync because an Ingress not managed by a App already routes
+	// one of its hosts and paths.
+	MessageHostConflictIngress = "Host %q path %q is already routed by Ingress %q"
+)
+
+// ingressRoute is a host and path routed by the Ingress of a App.
+type ingressRoute struct {
+	host string
+	path string
+}
+
+// ingressConflict describes a route claimed by two Apps, or by a App and an
+// Ingress not managed by a App.
+type ingressConflict struct {
+	route ingressRoute
+	// owner is the name of the App already routing the route, or of the
+	// Ingress when ingress is set.
+	owner   string
+	ingress bool
+}
+
+func (c *ingressConflict) String() string {
+	if c.ingress {
+		return fmt.Sprintf(MessageHostConflictIngress, c.route.host, c.route.path, c.owner)
+	}
+	return fmt.Sprintf(MessageHostConflict, c.route.host, c.route.path, c.owner)
+}
+
+// ingressRoutes returns the routes of a App that have an explicit host. Rules
+// without a host match every host and are not checked for conflicts, and an
+// App without an Ingress has no routes.
+func ingressRoutes(foo *groupkindv1alpha1.Foo) []ingressRoute {
+	if !ingressEnabled(foo) {
+		return nil
+	}
+	var routes []ingressRoute
+	for _, rule := range foo.Spec.Ingress.Rules {
+		if rule.Host == "" {
+			continue
+		}
+		for _, p := range rule.Paths {
+			routes = append(routes, ingressRoute{host: rule.Host, path: ingressPath(p)})
+		}
+	}
+	return routes
+}
+
+// findIngressConflict returns the first route of foo that is also routed by
+// another App of the same namespace, or by an Ingress of the namespace not
+// managed by one of the Apps the controller watches, or nil. The older App keeps the route, so only the newer
+// one of a conflicting pair reports the conflict. Ingresses not managed by a
+// App always keep theirs. With a label selector only the Ingresses matching
+// it are seen here; the validating webhook checks all of them.
+func (c *Controller) findIngressConflict(foo *groupkindv1alpha1.Foo) *ingressConflict {
+	routes := ingressRoutes(foo)
+	if len(routes) == 0 {
+		return nil
+	}
+	claimed := make(map[ingressRoute]bool, len(routes))
+	for _, r := range routes {
+		claimed[r] = true
+	}
+
+	others, err := c.foosLister.Foos(foo.Namespace).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to list apps in namespace %q: %s", foo.Namespace, err.Error()))
+		return nil
+	}
+	for _, other := range others {
+		if other.UID == foo.UID || !other.DeletionTimestamp.IsZero() || !olderThan(other, foo) {
+			continue
+		}
+		for _, r := range ingressRoutes(other) {
+			if claimed[r] {
+				return &ingressConflict{route: r, owner: other.Name}
+			}
+		}
+	}
+
+	// The Ingresses of the Apps listed above were checked through their App.
+	ingresses, err := c.ingressLister.Ingresses(foo.Namespace).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to list ingresses in namespace %q: %s", foo.Namespace, err.Error()))
+		return nil
+	}
+	for _, ingress := range unmanagedIngresses(ingresses, append(others, foo)) {
+		for _, r := range ingressObjectRoutes(ingress) {
+			if claimed[r] {
+				return &ingressConflict{route: r, owner: ingress.Name, ingress: true}
+			}
+		}
+	}
+	return nil
+}
+
+// unmanagedIngresses returns the Ingresses not being deleted and not
+// controlled by one of foos. The Ingresses of Apps that are not listed, because
+// they are outside the label selector or the watched namespaces, are
+// unmanaged as far as foos are concerned.
+func unmanagedIngresses(ingresses []*v1.Ingress, foos []*groupkindv1alpha1.Foo) []*v1.Ingress {
+	managed := make(map[types.UID]bool, len(foos))
+	for _, foo := range foos {
+		if foo.UID != "" {
+			managed[foo.UID] = true
+		}
+	}
+	var unmanaged []*v1.Ingress
+	for _, ingress := range ingresses {
+		if ingress.DeletionTimestamp != nil {
+			continue
+		}
+		if ref := metav1.GetControllerOf(ingress); ref != nil && managed[ref.UID] {
+			continue
+		}
+		unmanaged = append(unmanaged, ingress)
+	}
+	return unmanaged
+}
+
+// ingressObjectRoutes returns the routes of an Ingress that have an explicit
+// host.
+func ingressObjectRoutes(ingress *v1.Ingress) []ingressRoute {
+	var routes []ingressRoute
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host == "" || rule.HTTP == nil {
+			continue
+		}
+		for _, p := range rule.HTTP.Paths {
+			routes = append(routes, ingressRoute{host: rule.Host, path: ingressPath(groupkindv1alpha1.IngressPath{Path: p.Path})})
+		}
+	}
+	return routes
+}
+
+// olderThan orders Apps by creation time, then by name.
+func olderThan(a, b *groupkindv1alpha1.Foo) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+)
+
+// LeaderElectionOptions configures the Lease based leader election that lets
+// several replicas of the controller run with only one of them reconciling.
+type LeaderElectionOptions struct {
+	// Enabled turns leader election on. Without it the controller starts
+	// reconciling right away.
+	Enabled bool `json:"enabled,omitempty"`
+	// LeaseName and LeaseNamespace locate the Lease object used as the lock.
+	LeaseName      string `json:"leaseName,omitempty"`
+	LeaseNamespace string `json:"leaseNamespace,omitempty"`
+	// Identity names this replica in the Lease. Defaults to the hostname
+	// with a random suffix.
+	Identity string `json:"identity,omitempty"`
+	// LeaseDuration is how long non-leaders wait before trying to take over
+	// a Lease that is no longer renewed.
+	LeaseDuration metav1.Duration `json:"leaseDuration,omitempty"`
+	// RenewDeadline is how long the leader keeps retrying to renew the Lease
+	// before giving up leadership.
+	RenewDeadline metav1.Duration `json:"renewDeadline,omitempty"`
+	// RetryPeriod is how long replicas wait between attempts.
+	RetryPeriod metav1.Duration `json:"retryPeriod,omitempty"`
+}
+
+// AddFlags registers the leader election flags on fs.
+func (o *LeaderElectionOptions) AddFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&o.Enabled, "leader-elect", false, "Elect a leader among the controller replicas before reconciling. Required when running more than one replica.")
+	fs.StringVar(&o.LeaseName, "leader-elect-lease-name", controllerAgentName, "Name of the Lease object used for leader election.")
+	fs.StringVar(&o.LeaseNamespace, "leader-elect-lease-namespace", "default", "Namespace of the Lease object used for leader election.")
+	fs.StringVar(&o.Identity, "leader-elect-identity", "", "Identity of this replica in the Lease. Defaults to the hostname with a random suffix.")
+	fs.DurationVar(&o.LeaseDuration.Duration, "leader-elect-lease-duration", 15*time.Second, "Duration non-leader replicas wait before trying to take over an unrenewed Lease.")
+	fs.DurationVar(&o.RenewDeadline.Duration, "leader-elect-renew-deadline", 10*time.Second, "Duration the leader retries renewing the Lease before stepping down.")
+	fs.DurationVar(&o.RetryPeriod.Duration, "leader-elect-retry-period", 2*time.Second, "Duration replicas wait between leader election attempts.")
+}
+
+// Validate checks the durations of the leader election the way
+// leaderelection.NewLeaderElector does, so that bad values are reported at
+// startup rather than by a panic once the controller runs.
+func (o *LeaderElectionOptions) Validate() error {
+	leaseDuration, renewDeadline, retryPeriod := o.LeaseDuration.Duration, o.RenewDeadline.Duration, o.RetryPeriod.Duration
+	if leaseDuration <= 0 || renewDeadline <= 0 || retryPeriod <= 0 {
+		return fmt.Errorf("leader election lease duration, renew deadline and retry period must be positive, got %s, %s and %s", leaseDuration, renewDeadline, retryPeriod)
+	}
+	if leaseDuration <= renewDeadline {
+		return fmt.Errorf("leader election lease duration %s must be greater than the renew deadline %s", leaseDuration, renewDeadline)
+	}
+	if minRenewDeadline := time.Duration(leaderelection.JitterFactor * float64(retryPeriod)); renewDeadline <= minRenewDeadline {
+		return fmt.Errorf("leader election renew deadline %s must be greater than %v times the retry period %s", renewDeadline, leaderelection.JitterFactor, retryPeriod)
+	}
+	return nil
+}
+
+// runWithLeaderElection calls run once this replica holds the Lease, with a
+// stop channel that is closed when leadership is lost or stopCh is closed.
+// The Lease is released on shutdown so another replica can take over without
+// waiting for it to expire. Losing the Lease for any other reason exits the
+// process, as the informer caches can't be trusted to be stopped cleanly.
+// watchDog, when not nil, reports a leader that failed to renew its Lease.
+func runWithLeaderElection(opts LeaderElectionOptions, kubeClient kubernetes.Interface, watchDog *leaderelection.HealthzAdaptor, stopCh <-chan struct{}, run func(stopCh <-chan struct{})) error {
+	identity := opts.Identity
+	if identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("failed to get hostname for leader election identity: %s", err.Error())
+		}
+		identity = hostname + "_" + string(uuid.NewUUID())
+	}
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Namespace: opts.LeaseNamespace,
+			Name:      opts.LeaseName,
+		},
+		Client: kubeClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: identity,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	// started and finished let us wait for the workers to be done before
+	// returning, so the process doesn't exit under them.
+	started := make(chan struct{})
+	finished := make(chan struct{})
+
+	klog.Infof("Waiting to acquire lease %s/%s as %s", opts.LeaseNamespace, opts.LeaseName, identity)
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		ReleaseOnCancel: true,
+		LeaseDuration:   opts.LeaseDuration.Duration,
+		RenewDeadline:   opts.RenewDeadline.Duration,
+		RetryPeriod:     opts.RetryPeriod.Duration,
+		Name:            opts.LeaseName,
+		WatchDog:        watchDog,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				close(started)
+				defer close(finished)
+				klog.Infof("Acquired lease %s/%s", opts.LeaseNamespace, opts.LeaseName)
+				run(ctx.Done())
+			},
+			OnStoppedLeading: func() {
+				select {
+				case <-stopCh:
+					klog.Infof("Released lease %s/%s", opts.LeaseNamespace, opts.LeaseName)
+				default:
+					klog.Fatalf("Lost lease %s/%s", opts.LeaseNamespace, opts.LeaseName)
+				}
+			},
+			OnNewLeader: func(leader string) {
+				if leader != identity {
+					klog.Infof("Lease %s/%s is held by %s", opts.LeaseNamespace, opts.LeaseName, leader)
+				}
+			},
+		},
+	})
+
+	select {
+	case <-started:
+		<-finished
+	default:
+	}
+	return nil
+}
@@ -1,27 +1,41 @@
 package main
 
 import (
+	"context"
 	clientset "controller-crd/pkg/generated/clientset/versioned"
 	groupkindinformers_externalversions "controller-crd/pkg/generated/informers/externalversions"
+	"flag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/klog/v2"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 )
 
+var options = NewOptions()
+
 var onlyOneSignalHandler = make(chan struct{})
 var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
 
 func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+	if err := options.Complete(flag.CommandLine); err != nil {
+		klog.Fatalf("Error loading config: %s", err.Error())
+	}
+	if err := options.Validate(); err != nil {
+		klog.Fatalf("Invalid options: %s", err.Error())
+	}
 
 	// set up signals so we handle the first shutdown signal gracefully
 	stopCh := SetupSignalHandler()
-	//cfg, err := clientcmd.BuildConfigFromFlags(masterURL, kubeconfig)
-	cfg, err := clientcmd.BuildConfigFromFlags("", clientcmd.RecommendedHomeFile)
+	cfg, err := options.RESTConfig()
 	if err != nil {
 		klog.Fatalf("Error building kubeconfig: %s", err.Error())
 	}
@@ -37,24 +51,139 @@ func main() {
 		klog.Fatalf("Error building app clientset: %s", err.Error())
 	}
 
-	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, time.Second*30)
-	groupKindInformerFactory := groupkindinformers_externalversions.NewSharedInformerFactory(groupKindClient, time.Second*30)
+	// One pair of informer factories per watched namespace, so that the
+	// controller only needs RBAC in those namespaces. The label selector
+	// applies to the Apps and to their children, which carry the labels of
+	// their App. Children that predate those labels are labelled by
+	// labelChildren before the informers start.
+	tweakListOptions := func(o *metav1.ListOptions) {
+		o.LabelSelector = options.LabelSelector
+	}
+	var kubeInformerFactories []kubeinformers.SharedInformerFactory
+	var groupKindInformerFactories []groupkindinformers_externalversions.SharedInformerFactory
+	var informers []Informers
+	for _, namespace := range options.WatchedNamespaces() {
+		kubeInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, options.ResyncPeriod.Duration,
+			kubeinformers.WithNamespace(namespace),
+			kubeinformers.WithTweakListOptions(tweakListOptions))
+		groupKindInformerFactory := groupkindinformers_externalversions.NewSharedInformerFactoryWithOptions(groupKindClient, options.ResyncPeriod.Duration,
+			groupkindinformers_externalversions.WithNamespace(namespace),
+			groupkindinformers_externalversions.WithTweakListOptions(tweakListOptions))
+		kubeInformerFactories = append(kubeInformerFactories, kubeInformerFactory)
+		groupKindInformerFactories = append(groupKindInformerFactories, groupKindInformerFactory)
+		informers = append(informers, Informers{
+			Namespace:                namespace,
+			Deployments:              kubeInformerFactory.Apps().V1().Deployments(),
+			Services:                 kubeInformerFactory.Core().V1().Services(),
+			Ingresses:                kubeInformerFactory.Networking().V1().Ingresses(),
+			Foos:                     groupKindInformerFactory.Groupkind().V1alpha1().Foos(),
+			HorizontalPodAutoscalers: kubeInformerFactory.Autoscaling().V2().HorizontalPodAutoscalers(),
+		})
+	}
+
+	controller := NewController(kubeClient, groupKindClient, informers,
+		ControllerOptions{
+			ReconcileMode:    options.ReconcileMode,
+			CacheSyncTimeout: options.CacheSyncTimeout.Duration,
+		})
 
-	controller := NewController(kubeClient, groupKindClient,
-		kubeInformerFactory.Apps().V1().Deployments(),
-		kubeInformerFactory.Core().V1().Services(),
-		kubeInformerFactory.Networking().V1().Ingresses(),
-		groupKindInformerFactory.Groupkind().V1alpha1().Foos())
+	registerInformerMetrics(informers)
+	// The leader election watchdog fails the liveness probe of a leader that
+	// could not renew its lease for longer than this grace period.
+	var watchDog *leaderelection.HealthzAdaptor
+	if options.LeaderElection.Enabled {
+		watchDog = leaderelection.NewLeaderHealthzAdaptor(20 * time.Second)
+	}
+	health := newHealthChecker(controller, options.WorkerStallTimeout.Duration, watchDog)
+
+	// Metrics and probes are served by every replica, leader or not.
+	if options.MetricsBindAddress != "0" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metricsHandler())
+		go serveHTTP(options.MetricsBindAddress, "", mux, stopCh)
+	}
+	if options.HealthProbeBindAddress != "0" {
+		mux := http.NewServeMux()
+		health.install(mux)
+		go serveHTTP(options.HealthProbeBindAddress, "", mux, stopCh)
+	}
+	// The webhooks are served by every replica too, and read Apps from the
+	// informers of the controller, so the App informers are started now
+	// rather than once the lease is acquired. Starting them again in run is
+	// a no-op. The Services they read come from informers of their own,
+	// started here as well.
+	if options.WebhookBindAddress != "0" {
+		webhookInformers, webhookInformerFactories := webhookInformers(kubeClient, informers, options.ResyncPeriod.Duration)
+		webhooks := newWebhookServer(webhookInformers)
+		for _, factory := range groupKindInformerFactories {
+			factory.Start(stopCh)
+		}
+		for _, factory := range webhookInformerFactories {
+			factory.Start(stopCh)
+		}
+		mux := http.NewServeMux()
+		webhooks.install(mux)
+		go serveHTTP(options.WebhookBindAddress, options.WebhookCertDir, mux, stopCh)
+	}
 
-	// notice that there is no need to run Start methods in a separate goroutine. (i.e. go kubeInformerFactory.Start(stopCh)
-	// Start method is non-blocking and runs all registered informers in a dedicated goroutine.
-	kubeInformerFactory.Start(stopCh)
-	groupKindInformerFactory.Start(stopCh)
-	//controller运行后，就是从队列里面开始拿数据了。
-	if err = controller.Run(2, stopCh); err != nil {
-		klog.Fatalf("Error running controller: %s", err.Error())
+	run := func(stopCh <-chan struct{}) {
+		health.setLeading()
+		if options.LabelSelector != "" {
+			for _, namespace := range options.WatchedNamespaces() {
+				if err := labelChildren(context.TODO(), kubeClient, groupKindClient, namespace, options.LabelSelector); err != nil {
+					klog.Fatalf("Error labelling the children of apps: %s", err.Error())
+				}
+			}
+		}
+		// notice that there is no need to run Start methods in a separate goroutine. (i.e. go kubeInformerFactory.Start(stopCh)
+		// Start method is non-blocking and runs all registered informers in a dedicated goroutine.
+		for _, factory := range kubeInformerFactories {
+			factory.Start(stopCh)
+		}
+		for _, factory := range groupKindInformerFactories {
+			factory.Start(stopCh)
+		}
+		//controller运行后，就是从队列里面开始拿数据了。
+		if err := controller.Run(options.Workers, stopCh); err != nil {
+			klog.Fatalf("Error running controller: %s", err.Error())
+		}
 	}
 
+	if !options.LeaderElection.Enabled {
+		run(stopCh)
+		return
+	}
+	// Only the replica holding the lease starts its informers and workers.
+	if err = runWithLeaderElection(options.LeaderElection, kubeClient, watchDog, stopCh, run); err != nil {
+		klog.Fatalf("Error running leader election: %s", err.Error())
+	}
+}
+
+func init() {
+	options.AddFlags(flag.CommandLine)
+}
+
+// serveHTTP serves handler on addr until stopCh is closed. When certDir is
+// set, handler is served over TLS with the tls.crt and tls.key of certDir.
+func serveHTTP(addr, certDir string, handler http.Handler, stopCh <-chan struct{}) {
+	server := &http.Server{Addr: addr, Handler: handler}
+	go func() {
+		<-stopCh
+		if err := server.Shutdown(context.Background()); err != nil {
+			klog.Errorf("Error shutting down HTTP server on %s: %s", addr, err.Error())
+		}
+	}()
+	var err error
+	if certDir == "" {
+		klog.Infof("Serving HTTP on %s", addr)
+		err = server.ListenAndServe()
+	} else {
+		klog.Infof("Serving HTTPS on %s", addr)
+		err = server.ListenAndServeTLS(filepath.Join(certDir, "tls.crt"), filepath.Join(certDir, "tls.key"))
+	}
+	if err != nil && err != http.ErrServerClosed {
+		klog.Fatalf("Error serving HTTP on %s: %s", addr, err.Error())
+	}
 }
 
 // SetupSignalHandler registered for SIGTERM and SIGINT. A stop channel is returned
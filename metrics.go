@@ -0,0 +1,198 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const metricsNamespace = "controller_crd"
+
+// metricsRegistry holds every metric exposed on /metrics.
+var metricsRegistry = prometheus.NewRegistry()
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of App syncs by result.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+	}, []string{"result"})
+	reconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_total",
+		Help:      "Number of App syncs by result.",
+	}, []string{"result"})
+	childOperationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "child_operations_total",
+		Help:      "Number of writes to the children of Apps by kind and operation.",
+	}, []string{"kind", "operation"})
+)
+
+// Results of a sync, used as the result label.
+const (
+	resultSuccess = "success"
+	resultError   = "error"
+)
+
+// Operations on children, used as the operation label.
+const (
+	operationCreate = "create"
+	operationUpdate = "update"
+	operationApply  = "apply"
+	operationDelete = "delete"
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		reconcileDuration,
+		reconcileTotal,
+		childOperationsTotal,
+		workqueueDepth,
+		workqueueAdds,
+		workqueueLatency,
+		workqueueWorkDuration,
+		workqueueUnfinishedWork,
+		workqueueLongestRunningProcessor,
+		workqueueRetries,
+	)
+	// The provider must be set before the workqueue is created.
+	workqueue.SetProvider(workqueueMetricsProvider{})
+}
+
+// recordReconcile records the duration and result of a sync.
+func recordReconcile(start time.Time, err error) {
+	result := resultSuccess
+	if err != nil {
+		result = resultError
+	}
+	reconcileDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+	reconcileTotal.WithLabelValues(result).Inc()
+}
+
+// recordChildOperation counts a successful write to a child of a App.
+func recordChildOperation(kind, operation string) {
+	childOperationsTotal.WithLabelValues(kind, operation).Inc()
+}
+
+// registerInformerMetrics exposes the number of objects in the cache of each
+// informer, by resource and namespace.
+func registerInformerMetrics(informers []Informers) {
+	desc := prometheus.NewDesc(metricsNamespace+"_informer_cache_objects", "Number of objects in the informer cache by resource and watched namespace.", []string{"resource", "namespace"}, nil)
+	metricsRegistry.MustRegister(informerCacheCollector{desc: desc, informers: informers})
+}
+
+type informerCacheCollector struct {
+	desc      *prometheus.Desc
+	informers []Informers
+}
+
+func (c informerCacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c informerCacheCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, i := range c.informers {
+		for resource, informer := range map[string]cache.SharedIndexInformer{
+			"deployments":              i.Deployments.Informer(),
+			"services":                 i.Services.Informer(),
+			"ingresses":                i.Ingresses.Informer(),
+			"foos":                     i.Foos.Informer(),
+			"horizontalpodautoscalers": i.HorizontalPodAutoscalers.Informer(),
+		} {
+			ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(len(informer.GetStore().ListKeys())), resource, i.Namespace)
+		}
+	}
+}
+
+// metricsHandler serves the registered metrics.
+func metricsHandler() http.Handler {
+	return promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})
+}
+
+// workqueueMetricsProvider implements workqueue.MetricsProvider with
+// Prometheus metrics labelled by queue name.
+type workqueueMetricsProvider struct{}
+
+var (
+	workqueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "workqueue",
+		Name:      "depth",
+		Help:      "Current depth of the workqueue.",
+	}, []string{"name"})
+	workqueueAdds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "workqueue",
+		Name:      "adds_total",
+		Help:      "Number of adds handled by the workqueue.",
+	}, []string{"name"})
+	workqueueLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "workqueue",
+		Name:      "queue_duration_seconds",
+		Help:      "How long an item stays in the workqueue before being requested.",
+		Buckets:   prometheus.ExponentialBuckets(10e-9, 10, 10),
+	}, []string{"name"})
+	workqueueWorkDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "workqueue",
+		Name:      "work_duration_seconds",
+		Help:      "How long processing an item from the workqueue takes.",
+		Buckets:   prometheus.ExponentialBuckets(10e-9, 10, 10),
+	}, []string{"name"})
+	workqueueUnfinishedWork = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "workqueue",
+		Name:      "unfinished_work_seconds",
+		Help:      "How many seconds of work has been done that is in progress and hasn't been observed by work_duration. Large values indicate stuck threads.",
+	}, []string{"name"})
+	workqueueLongestRunningProcessor = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "workqueue",
+		Name:      "longest_running_processor_seconds",
+		Help:      "How many seconds the longest running processor of the workqueue has been running.",
+	}, []string{"name"})
+	workqueueRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "workqueue",
+		Name:      "retries_total",
+		Help:      "Number of retries handled by the workqueue.",
+	}, []string{"name"})
+)
+
+func (workqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return workqueueDepth.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return workqueueAdds.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return workqueueLatency.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return workqueueWorkDuration.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return workqueueUnfinishedWork.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return workqueueLongestRunningProcessor.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return workqueueRetries.WithLabelValues(name)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	groupkindv1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	groupkindinformer "controller-crd/pkg/generated/informers/externalversions/groupkind/v1alpha1"
+	groupkindlister "controller-crd/pkg/generated/listers/groupkind/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	v12 "k8s.io/client-go/informers/apps/v1"
+	autoscalingv2informers "k8s.io/client-go/informers/autoscaling/v2"
+	v13 "k8s.io/client-go/informers/core/v1"
+	v14 "k8s.io/client-go/informers/networking/v1"
+	v15 "k8s.io/client-go/listers/apps/v1"
+	autoscalingv2listers "k8s.io/client-go/listers/autoscaling/v2"
+	v16 "k8s.io/client-go/listers/core/v1"
+	v17 "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Informers are the informers the controller reads Apps and their children
+// from, all scoped to the same namespace.
+type Informers struct {
+	// Namespace is the namespace the informers watch, metav1.NamespaceAll
+	// for all namespaces.
+	Namespace   string
+	Deployments v12.DeploymentInformer
+	Services    v13.ServiceInformer
+	Ingresses   v14.IngressInformer
+	Foos        groupkindinformer.FooInformer
+	// HorizontalPodAutoscalers are read from autoscaling/v2, served since
+	// Kubernetes 1.23.
+	HorizontalPodAutoscalers autoscalingv2informers.HorizontalPodAutoscalerInformer
+}
+
+// informerSynced is the HasSynced of an informer, with the name the
+// informer is reported by while it hasn't synced.
+type informerSynced struct {
+	name   string
+	synced cache.InformerSynced
+}
+
+// synced returns the HasSynced of each informer of i, named after the
+// resource and, unless all namespaces are watched, the namespace.
+func (i Informers) synced() []informerSynced {
+	name := func(resource string) string {
+		if i.Namespace == metav1.NamespaceAll {
+			return resource
+		}
+		return i.Namespace + "/" + resource
+	}
+	return []informerSynced{
+		{name: name("deployments"), synced: i.Deployments.Informer().HasSynced},
+		{name: name("services"), synced: i.Services.Informer().HasSynced},
+		{name: name("ingresses"), synced: i.Ingresses.Informer().HasSynced},
+		{name: name("horizontalpodautoscalers"), synced: i.HorizontalPodAutoscalers.Informer().HasSynced},
+		{name: name("foos"), synced: i.Foos.Informer().HasSynced},
+	}
+}
+
+// emptyIndexer backs the listers of the namespaces that aren't watched, so
+// that a lookup there finds nothing instead of failing.
+func emptyIndexer() cache.Indexer {
+	return cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+}
+
+// The listers below dispatch each lookup to the lister of the informers
+// watching the namespace, so that the controller can run against a set of
+// namespaces with one informer factory per namespace.
+
+type namespacedDeploymentLister map[string]v15.DeploymentLister
+
+func (l namespacedDeploymentLister) List(selector labels.Selector) ([]*appsv1.Deployment, error) {
+	var all []*appsv1.Deployment
+	for _, lister := range l {
+		ret, err := lister.List(selector)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, ret...)
+	}
+	return all, nil
+}
+
+func (l namespacedDeploymentLister) Deployments(namespace string) v15.DeploymentNamespaceLister {
+	if lister, ok := l[namespace]; ok {
+		return lister.Deployments(namespace)
+	}
+	if lister, ok := l[metav1.NamespaceAll]; ok {
+		return lister.Deployments(namespace)
+	}
+	return v15.NewDeploymentLister(emptyIndexer()).Deployments(namespace)
+}
+
+type namespacedServiceLister map[string]v16.ServiceLister
+
+func (l namespacedServiceLister) List(selector labels.Selector) ([]*corev1.Service, error) {
+	var all []*corev1.Service
+	for _, lister := range l {
+		ret, err := lister.List(selector)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, ret...)
+	}
+	return all, nil
+}
+
+func (l namespacedServiceLister) Services(namespace string) v16.ServiceNamespaceLister {
+	if lister, ok := l[namespace]; ok {
+		return lister.Services(namespace)
+	}
+	if lister, ok := l[metav1.NamespaceAll]; ok {
+		return lister.Services(namespace)
+	}
+	return v16.NewServiceLister(emptyIndexer()).Services(namespace)
+}
+
+type namespacedIngressLister map[string]v17.IngressLister
+
+func (l namespacedIngressLister) List(selector labels.Selector) ([]*v1.Ingress, error) {
+	var all []*v1.Ingress
+	for _, lister := range l {
+		ret, err := lister.List(selector)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, ret...)
+	}
+	return all, nil
+}
+
+func (l namespacedIngressLister) Ingresses(namespace string) v17.IngressNamespaceLister {
+	if lister, ok := l[namespace]; ok {
+		return lister.Ingresses(namespace)
+	}
+	if lister, ok := l[metav1.NamespaceAll]; ok {
+		return lister.Ingresses(namespace)
+	}
+	return v17.NewIngressLister(emptyIndexer()).Ingresses(namespace)
+}
+
+type namespacedHorizontalPodAutoscalerLister map[string]autoscalingv2listers.HorizontalPodAutoscalerLister
+
+func (l namespacedHorizontalPodAutoscalerLister) List(selector labels.Selector) ([]*autoscalingv2.HorizontalPodAutoscaler, error) {
+	var all []*autoscalingv2.HorizontalPodAutoscaler
+	for _, lister := range l {
+		ret, err := lister.List(selector)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, ret...)
+	}
+	return all, nil
+}
+
+func (l namespacedHorizontalPodAutoscalerLister) HorizontalPodAutoscalers(namespace string) autoscalingv2listers.HorizontalPodAutoscalerNamespaceLister {
+	if lister, ok := l[namespace]; ok {
+		return lister.HorizontalPodAutoscalers(namespace)
+	}
+	if lister, ok := l[metav1.NamespaceAll]; ok {
+		return lister.HorizontalPodAutoscalers(namespace)
+	}
+	return autoscalingv2listers.NewHorizontalPodAutoscalerLister(emptyIndexer()).HorizontalPodAutoscalers(namespace)
+}
+
+type namespacedFooLister map[string]groupkindlister.FooLister
+
+func (l namespacedFooLister) List(selector labels.Selector) ([]*groupkindv1alpha1.Foo, error) {
+	var all []*groupkindv1alpha1.Foo
+	for _, lister := range l {
+		ret, err := lister.List(selector)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, ret...)
+	}
+	return all, nil
+}
+
+func (l namespacedFooLister) Foos(namespace string) groupkindlister.FooNamespaceLister {
+	if lister, ok := l[namespace]; ok {
+		return lister.Foos(namespace)
+	}
+	if lister, ok := l[metav1.NamespaceAll]; ok {
+		return lister.Foos(namespace)
+	}
+	return groupkindlister.NewFooLister(emptyIndexer()).Foos(namespace)
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// Options is the configuration of the controller binary. It is read from an
+// optional YAML or JSON config file, and flags given on the command line
+// override the values of the file.
+type Options struct {
+	// Kubeconfig is the path to a kubeconfig file. When neither Kubeconfig
+	// nor MasterURL is set, the in-cluster config is used, falling back to
+	// ~/.kube/config.
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// MasterURL overrides the address of the API server in the kubeconfig.
+	MasterURL string `json:"master,omitempty"`
+	// QPS and Burst limit the requests made to the API server.
+	QPS   float32 `json:"qps,omitempty"`
+	Burst int     `json:"burst,omitempty"`
+
+	// Workers is the number of Apps synced concurrently.
+	Workers int `json:"workers,omitempty"`
+	// ResyncPeriod is how often the informers resync their whole cache.
+	ResyncPeriod metav1.Duration `json:"resyncPeriod,omitempty"`
+	// CacheSyncTimeout is how long the controller waits for its informer
+	// caches to sync at startup before exiting. Zero waits forever.
+	CacheSyncTimeout metav1.Duration `json:"cacheSyncTimeout,omitempty"`
+	// Namespaces restricts the controller to a set of namespaces, so that it
+	// only needs RBAC in those. All namespaces are watched when empty.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// LabelSelector restricts the controller to the Apps and children
+	// matching it. Children carry the labels of their App.
+	LabelSelector string `json:"labelSelector,omitempty"`
+	// ReconcileMode selects how children are written.
+	ReconcileMode ReconcileMode `json:"reconcileMode,omitempty"`
+	// MetricsBindAddress is the address /metrics is served on. "0" turns
+	// the endpoint off.
+	MetricsBindAddress string `json:"metricsBindAddress,omitempty"`
+	// HealthProbeBindAddress is the address /healthz and /readyz are served
+	// on. "0" turns the endpoints off.
+	HealthProbeBindAddress string `json:"healthProbeBindAddress,omitempty"`
+	// WorkerStallTimeout is how long the workers may go without dequeuing
+	// while the workqueue is not empty before /healthz fails.
+	WorkerStallTimeout metav1.Duration `json:"workerStallTimeout,omitempty"`
+	// WebhookBindAddress is the address the admission webhooks are served
+	// on over TLS. "0" turns the webhooks off.
+	WebhookBindAddress string `json:"webhookBindAddress,omitempty"`
+	// WebhookCertDir is the directory holding the tls.crt and tls.key the
+	// webhooks are served with.
+	WebhookCertDir string `json:"webhookCertDir,omitempty"`
+	// Verbosity is the klog verbosity, for config files. On the command line
+	// use -v.
+	Verbosity *int `json:"verbosity,omitempty"`
+
+	LeaderElection LeaderElectionOptions `json:"leaderElection,omitempty"`
+
+	// configFile is only read from the command line.
+	configFile string
+}
+
+// NewOptions returns the default options.
+func NewOptions() *Options {
+	return &Options{
+		QPS:                    20,
+		Burst:                  30,
+		Workers:                2,
+		ResyncPeriod:           metav1.Duration{Duration: 30 * time.Second},
+		CacheSyncTimeout:       metav1.Duration{Duration: 2 * time.Minute},
+		ReconcileMode:          ReconcileModeUpdate,
+		MetricsBindAddress:     ":8080",
+		HealthProbeBindAddress: ":8081",
+		WorkerStallTimeout:     metav1.Duration{Duration: 2 * time.Minute},
+		WebhookBindAddress:     "0",
+		WebhookCertDir:         "/tmp/k8s-webhook-server/serving-certs",
+	}
+}
+
+// AddFlags registers the flags of the controller binary on fs.
+func (o *Options) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.configFile, "config", "", "Path to a YAML or JSON config file. Flags given on the command line override its values.")
+	fs.StringVar(&o.Kubeconfig, "kubeconfig", o.Kubeconfig, "Path to a kubeconfig. Only required if out-of-cluster.")
+	fs.StringVar(&o.MasterURL, "master", o.MasterURL, "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
+	fs.Var(float32Value{&o.QPS}, "kube-api-qps", "Queries per second allowed to the API server.")
+	fs.IntVar(&o.Burst, "kube-api-burst", o.Burst, "Burst of queries allowed to the API server.")
+	fs.IntVar(&o.Workers, "workers", o.Workers, "Number of Apps synced concurrently.")
+	fs.DurationVar(&o.ResyncPeriod.Duration, "resync-period", o.ResyncPeriod.Duration, "How often the informers resync their whole cache.")
+	fs.DurationVar(&o.CacheSyncTimeout.Duration, "cache-sync-timeout", o.CacheSyncTimeout.Duration, "How long to wait for the informer caches to sync at startup before exiting. 0 waits forever.")
+	fs.Var(stringSliceValue{&o.Namespaces}, "namespaces", "Comma-separated namespaces to watch. All namespaces are watched when empty.")
+	fs.StringVar(&o.LabelSelector, "label-selector", o.LabelSelector, "Only sync the Apps, and watch the children, matching this label selector.")
+	fs.Var(reconcileModeValue{&o.ReconcileMode}, "reconcile-mode", "How children are written: 'update' for create and full-object updates, 'apply' for server-side apply with the controller-crd field manager.")
+	fs.StringVar(&o.MetricsBindAddress, "metrics-bind-address", o.MetricsBindAddress, "The address the /metrics endpoint binds to. Set to 0 to disable it.")
+	fs.StringVar(&o.HealthProbeBindAddress, "health-probe-bind-address", o.HealthProbeBindAddress, "The address the /healthz and /readyz endpoints bind to. Set to 0 to disable them.")
+	fs.DurationVar(&o.WorkerStallTimeout.Duration, "worker-stall-timeout", o.WorkerStallTimeout.Duration, "How long the workers may go without dequeuing while the workqueue is not empty before /healthz fails.")
+	fs.StringVar(&o.WebhookBindAddress, "webhook-bind-address", o.WebhookBindAddress, "The address the admission webhooks bind to, e.g. :9443. Set to 0 to disable them.")
+	fs.StringVar(&o.WebhookCertDir, "webhook-cert-dir", o.WebhookCertDir, "The directory holding the tls.crt and tls.key the admission webhooks are served with.")
+	o.LeaderElection.AddFlags(fs)
+}
+
+// Complete loads the config file, if any, and reapplies the flags that were
+// set on the command line on top of it. It must be called after fs.Parse.
+func (o *Options) Complete(fs *flag.FlagSet) error {
+	if o.configFile == "" {
+		return nil
+	}
+
+	set := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = f.Value.String()
+	})
+
+	data, err := os.ReadFile(o.configFile)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %s", err.Error())
+	}
+	if err := yaml.UnmarshalStrict(data, o); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %s", o.configFile, err.Error())
+	}
+
+	for name, value := range set {
+		if err := fs.Set(name, value); err != nil {
+			return err
+		}
+	}
+	if _, ok := set["v"]; !ok && o.Verbosity != nil {
+		if err := fs.Set("v", strconv.Itoa(*o.Verbosity)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks the options for values the controller can't run with.
+func (o *Options) Validate() error {
+	if o.Workers < 1 {
+		return fmt.Errorf("workers must be at least 1, got %d", o.Workers)
+	}
+	if o.ResyncPeriod.Duration < 0 {
+		return fmt.Errorf("resync period must not be negative, got %s", o.ResyncPeriod.Duration)
+	}
+	if o.CacheSyncTimeout.Duration < 0 {
+		return fmt.Errorf("cache sync timeout must not be negative, got %s", o.CacheSyncTimeout.Duration)
+	}
+	if _, err := ParseReconcileMode(string(o.ReconcileMode)); err != nil {
+		return err
+	}
+	if _, err := labels.Parse(o.LabelSelector); err != nil {
+		return fmt.Errorf("invalid label selector %q: %s", o.LabelSelector, err.Error())
+	}
+	if o.WebhookBindAddress != "0" && o.WebhookCertDir == "" {
+		return fmt.Errorf("webhook cert dir must be set when the webhooks are enabled")
+	}
+	for _, namespace := range o.Namespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return fmt.Errorf("invalid namespace %q: %s", namespace, strings.Join(errs, ", "))
+		}
+	}
+	if o.LeaderElection.Enabled {
+		if err := o.LeaderElection.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WatchedNamespaces returns the namespaces to run informers for, with
+// metav1.NamespaceAll standing for the whole cluster.
+func (o *Options) WatchedNamespaces() []string {
+	if len(o.Namespaces) == 0 {
+		return []string{metav1.NamespaceAll}
+	}
+	seen := map[string]bool{}
+	var namespaces []string
+	for _, namespace := range o.Namespaces {
+		if !seen[namespace] {
+			seen[namespace] = true
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
+
+// RESTConfig builds the client config from the kubeconfig and master flags,
+// using the in-cluster config when neither is set.
+func (o *Options) RESTConfig() (*rest.Config, error) {
+	var cfg *rest.Config
+	var err error
+	if o.Kubeconfig == "" && o.MasterURL == "" {
+		cfg, err = rest.InClusterConfig()
+		if err != nil {
+			klog.Infof("Not running in cluster (%s), using %s", err.Error(), clientcmd.RecommendedHomeFile)
+			cfg, err = clientcmd.BuildConfigFromFlags("", clientcmd.RecommendedHomeFile)
+		}
+	} else {
+		cfg, err = clientcmd.BuildConfigFromFlags(o.MasterURL, o.Kubeconfig)
+	}
+	if err != nil {
+		return nil, err
+	}
+	cfg.QPS = o.QPS
+	cfg.Burst = o.Burst
+	return cfg, nil
+}
+
+// float32Value is a flag.Value for a float32.
+type float32Value struct{ p *float32 }
+
+func (v float32Value) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return strconv.FormatFloat(float64(*v.p), 'g', -1, 32)
+}
+
+func (v float32Value) Set(s string) error {
+	f, err := strconv.ParseFloat(s, 32)
+	if err != nil {
+		return err
+	}
+	*v.p = float32(f)
+	return nil
+}
+
+// reconcileModeValue is a flag.Value for a ReconcileMode.
+type reconcileModeValue struct{ p *ReconcileMode }
+
+func (v reconcileModeValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return string(*v.p)
+}
+
+func (v reconcileModeValue) Set(s string) error {
+	mode, err := ParseReconcileMode(strings.TrimSpace(s))
+	if err != nil {
+		return err
+	}
+	*v.p = mode
+	return nil
+}
+
+// stringSliceValue is a flag.Value for a comma-separated list of strings.
+type stringSliceValue struct{ p *[]string }
+
+func (v stringSliceValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return strings.Join(*v.p, ",")
+}
+
+func (v stringSliceValue) Set(s string) error {
+	var values []string
+	for _, value := range strings.Split(s, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	*v.p = values
+	return nil
+}
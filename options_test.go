@@ -0,0 +1,157 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateLeaderElection(t *testing.T) {
+	tests := []struct {
+		name                                      string
+		enabled                                   bool
+		leaseDuration, renewDeadline, retryPeriod time.Duration
+		err                                       string
+	}{
+		{name: "defaults", enabled: true, leaseDuration: 15 * time.Second, renewDeadline: 10 * time.Second, retryPeriod: 2 * time.Second},
+		{
+			name: "lease duration not greater than renew deadline", enabled: true,
+			leaseDuration: 10 * time.Second, renewDeadline: 10 * time.Second, retryPeriod: 2 * time.Second,
+			err: "lease duration 10s must be greater than the renew deadline 10s",
+		},
+		{
+			name: "renew deadline within the jittered retry period", enabled: true,
+			leaseDuration: 15 * time.Second, renewDeadline: 6 * time.Second, retryPeriod: 5 * time.Second,
+			err: "renew deadline 6s must be greater than 1.2 times the retry period 5s",
+		},
+		{
+			name: "zero retry period", enabled: true,
+			leaseDuration: 15 * time.Second, renewDeadline: 10 * time.Second,
+			err: "must be positive",
+		},
+		{name: "disabled", leaseDuration: 10 * time.Second, renewDeadline: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewOptions()
+			o.LeaderElection = LeaderElectionOptions{
+				Enabled:       tt.enabled,
+				LeaseDuration: metav1.Duration{Duration: tt.leaseDuration},
+				RenewDeadline: metav1.Duration{Duration: tt.renewDeadline},
+				RetryPeriod:   metav1.Duration{Duration: tt.retryPeriod},
+			}
+			err := o.Validate()
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("expected no error, got %q", err.Error())
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("expected an error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestComplete(t *testing.T) {
+	const config = `
+workers: 4
+namespaces: [team-a, team-b]
+labelSelector: team=web
+resyncPeriod: 1m
+verbosity: 3
+`
+	tests := []struct {
+		name   string
+		config string
+		args   []string
+		// expected returns the expected options, starting from the defaults.
+		expected  func(o *Options)
+		verbosity int
+		err       string
+	}{
+		{
+			name:   "config file",
+			config: config,
+			expected: func(o *Options) {
+				o.Workers = 4
+				o.Namespaces = []string{"team-a", "team-b"}
+				o.LabelSelector = "team=web"
+				o.ResyncPeriod.Duration = time.Minute
+			},
+			verbosity: 3,
+		},
+		{
+			name:   "flags override the config file",
+			config: config,
+			args:   []string{"-workers", "8", "-namespaces", "team-c", "-v", "5"},
+			expected: func(o *Options) {
+				o.Workers = 8
+				o.Namespaces = []string{"team-c"}
+				o.LabelSelector = "team=web"
+				o.ResyncPeriod.Duration = time.Minute
+			},
+			verbosity: 5,
+		},
+		{
+			name: "flags without a config file",
+			args: []string{"-workers", "8", "-namespaces", "team-c,team-d", "-v", "5"},
+			expected: func(o *Options) {
+				o.Workers = 8
+				o.Namespaces = []string{"team-c", "team-d"}
+			},
+			verbosity: 5,
+		},
+		{
+			name:   "unknown field",
+			config: "worker: 4\n",
+			err:    "failed to parse config file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewOptions()
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			o.AddFlags(fs)
+			verbosity := fs.Int("v", 0, "")
+			args := tt.args
+			if tt.config != "" {
+				path := filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(path, []byte(tt.config), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				args = append([]string{"-config", path}, args...)
+			}
+			if err := fs.Parse(args); err != nil {
+				t.Fatal(err)
+			}
+
+			err := o.Complete(fs)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("expected an error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// The leader election defaults are set by AddFlags.
+			expected := NewOptions()
+			expected.AddFlags(flag.NewFlagSet("defaults", flag.ContinueOnError))
+			tt.expected(expected)
+			expected.configFile = o.configFile
+			expected.Verbosity = o.Verbosity
+			if !reflect.DeepEqual(expected, o) {
+				t.Errorf("expected options %+v, got %+v", expected, o)
+			}
+			if *verbosity != tt.verbosity {
+				t.Errorf("expected verbosity %d, got %d", tt.verbosity, *verbosity)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	groupkindv1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+// serviceEnabled reports whether the App asks for a Service.
+func serviceEnabled(foo *groupkindv1alpha1.Foo) bool {
+	spec := foo.Spec.Service
+	return spec != nil && (spec.Enabled == nil || *spec.Enabled)
+}
+
+// ingressEnabled reports whether the App asks for an Ingress. An Ingress
+// routes to the Service, so it is disabled along with it.
+func ingressEnabled(foo *groupkindv1alpha1.Foo) bool {
+	spec := foo.Spec.Ingress
+	return serviceEnabled(foo) && spec != nil && (spec.Enabled == nil || *spec.Enabled)
+}
+
+// autoscalingEnabled reports whether the App asks for a
+// HorizontalPodAutoscaler.
+func autoscalingEnabled(foo *groupkindv1alpha1.Foo) bool {
+	spec := foo.Spec.Autoscaling
+	return spec != nil && (spec.Enabled == nil || *spec.Enabled)
+}
+
+// serviceName is the name of the App's Service, defaulting to the name of
+// its Deployment.
+func serviceName(foo *groupkindv1alpha1.Foo) string {
+	if foo.Spec.Service != nil && foo.Spec.Service.Name != "" {
+		return foo.Spec.Service.Name
+	}
+	return foo.Spec.Deployment.Name
+}
+
+// ingressName is the name of the App's Ingress, defaulting to the name of
+// its Deployment.
+func ingressName(foo *groupkindv1alpha1.Foo) string {
+	if foo.Spec.Ingress != nil && foo.Spec.Ingress.Name != "" {
+		return foo.Spec.Ingress.Name
+	}
+	return foo.Spec.Deployment.Name
+}
+
+// ownedDeployments returns the Deployments of the App's namespace it
+// controls.
+func (c *Controller) ownedDeployments(foo *groupkindv1alpha1.Foo) ([]*appsv1.Deployment, error) {
+	deployments, err := c.deploymentsLister.Deployments(foo.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var owned []*appsv1.Deployment
+	for _, deployment := range deployments {
+		if metav1.IsControlledBy(deployment, foo) {
+			owned = append(owned, deployment)
+		}
+	}
+	return owned, nil
+}
+
+// ownedServices returns the Services of the App's namespace it controls.
+func (c *Controller) ownedServices(foo *groupkindv1alpha1.Foo) ([]*corev1.Service, error) {
+	services, err := c.serviceLister.Services(foo.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var owned []*corev1.Service
+	for _, service := range services {
+		if metav1.IsControlledBy(service, foo) {
+			owned = append(owned, service)
+		}
+	}
+	return owned, nil
+}
+
+// ownedIngresses returns the Ingresses of the App's namespace it controls.
+func (c *Controller) ownedIngresses(foo *groupkindv1alpha1.Foo) ([]*v1.Ingress, error) {
+	ingresses, err := c.ingressLister.Ingresses(foo.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var owned []*v1.Ingress
+	for _, ingress := range ingresses {
+		if metav1.IsControlledBy(ingress, foo) {
+			owned = append(owned, ingress)
+		}
+	}
+	return owned, nil
+}
+
+// ownedHorizontalPodAutoscalers returns the HorizontalPodAutoscalers of the
+// App's namespace it controls.
+func (c *Controller) ownedHorizontalPodAutoscalers(foo *groupkindv1alpha1.Foo) ([]*autoscalingv2.HorizontalPodAutoscaler, error) {
+	hpas, err := c.hpaLister.HorizontalPodAutoscalers(foo.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var owned []*autoscalingv2.HorizontalPodAutoscaler
+	for _, hpa := range hpas {
+		if metav1.IsControlledBy(hpa, foo) {
+			owned = append(owned, hpa)
+		}
+	}
+	return owned, nil
+}
+
+// deleteOwnedDeployments deletes the Deployments controlled by the App,
+// except the one named keep.
+func (c *Controller) deleteOwnedDeployments(foo *groupkindv1alpha1.Foo, keep string) error {
+	deployments, err := c.ownedDeployments(foo)
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments {
+		if deployment.Name == keep || deployment.DeletionTimestamp != nil {
+			continue
+		}
+		klog.V(4).Infof("Deleting deployment '%s/%s' no longer used by app '%s'", deployment.Namespace, deployment.Name, foo.Name)
+		err = c.kubeclientset.AppsV1().Deployments(deployment.Namespace).Delete(context.TODO(), deployment.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		recordChildOperation("Deployment", operationDelete)
+	}
+	return nil
+}
+
+// deleteOwnedServices deletes the Services controlled by the App, except the
+// one named keep.
+func (c *Controller) deleteOwnedServices(foo *groupkindv1alpha1.Foo, keep string) error {
+	services, err := c.ownedServices(foo)
+	if err != nil {
+		return err
+	}
+	for _, service := range services {
+		if service.Name == keep || service.DeletionTimestamp != nil {
+			continue
+		}
+		klog.V(4).Infof("Deleting service '%s/%s' no longer used by app '%s'", service.Namespace, service.Name, foo.Name)
+		err = c.kubeclientset.CoreV1().Services(service.Namespace).Delete(context.TODO(), service.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		recordChildOperation("Service", operationDelete)
+	}
+	return nil
+}
+
+// deleteOwnedIngresses deletes the Ingresses controlled by the App, except
+// the one named keep.
+func (c *Controller) deleteOwnedIngresses(foo *groupkindv1alpha1.Foo, keep string) error {
+	ingresses, err := c.ownedIngresses(foo)
+	if err != nil {
+		return err
+	}
+	for _, ingress := range ingresses {
+		if ingress.Name == keep || ingress.DeletionTimestamp != nil {
+			continue
+		}
+		klog.V(4).Infof("Deleting ingress '%s/%s' no longer used by app '%s'", ingress.Namespace, ingress.Name, foo.Name)
+		err = c.kubeclientset.NetworkingV1().Ingresses(ingress.Namespace).Delete(context.TODO(), ingress.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		recordChildOperation("Ingress", operationDelete)
+	}
+	return nil
+}
+
+// deleteOwnedHorizontalPodAutoscalers deletes the HorizontalPodAutoscalers
+// controlled by the App, except the one named keep.
+func (c *Controller) deleteOwnedHorizontalPodAutoscalers(foo *groupkindv1alpha1.Foo, keep string) error {
+	hpas, err := c.ownedHorizontalPodAutoscalers(foo)
+	if err != nil {
+		return err
+	}
+	for _, hpa := range hpas {
+		if hpa.Name == keep || hpa.DeletionTimestamp != nil {
+			continue
+		}
+		klog.V(4).Infof("Deleting horizontal pod autoscaler '%s/%s' no longer used by app '%s'", hpa.Namespace, hpa.Name, foo.Name)
+		err = c.kubeclientset.AutoscalingV2().HorizontalPodAutoscalers(hpa.Namespace).Delete(context.TODO(), hpa.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		recordChildOperation("HorizontalPodAutoscaler", operationDelete)
+	}
+	return nil
+}
+
+// pendingRenames describes the renames of the App's Deployment, Service and
+// Ingress that are still in progress: children it owns under their old name
+// that haven't disappeared from the informer caches yet.
+func (c *Controller) pendingRenames(foo *groupkindv1alpha1.Foo) ([]string, error) {
+	var renames []string
+	deployments, err := c.ownedDeployments(foo)
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments {
+		if deployment.Name != foo.Spec.Deployment.Name {
+			renames = append(renames, fmt.Sprintf("Deployment %q is being renamed to %q", deployment.Name, foo.Spec.Deployment.Name))
+		}
+	}
+	if serviceEnabled(foo) {
+		services, err := c.ownedServices(foo)
+		if err != nil {
+			return nil, err
+		}
+		for _, service := range services {
+			if service.Name != serviceName(foo) {
+				renames = append(renames, fmt.Sprintf("Service %q is being renamed to %q", service.Name, serviceName(foo)))
+			}
+		}
+	}
+	if ingressEnabled(foo) {
+		ingresses, err := c.ownedIngresses(foo)
+		if err != nil {
+			return nil, err
+		}
+		for _, ingress := range ingresses {
+			if ingress.Name != ingressName(foo) {
+				renames = append(renames, fmt.Sprintf("Ingress %q is being renamed to %q", ingress.Name, ingressName(foo)))
+			}
+		}
+	}
+	return renames, nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	groupkindv1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	clientset "controller-crd/pkg/generated/clientset/versioned"
+	groupkindscheme "controller-crd/pkg/generated/clientset/versioned/scheme"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// fooGVK is the GroupVersionKind a App is registered with, used in the owner
+// references of its children.
+var fooGVK = kindFor(groupkindscheme.Scheme, &groupkindv1alpha1.Foo{})
+
+// kindFor returns the GroupVersionKind obj is registered with in scheme. It
+// panics when obj isn't registered.
+func kindFor(scheme *runtime.Scheme, obj runtime.Object) schema.GroupVersionKind {
+	gvks, _, err := scheme.ObjectKinds(obj)
+	if err != nil {
+		panic(err)
+	}
+	return gvks[0]
+}
+
+// fixOwnerReferences points the owner references of a child naming a kind of
+// the App API group other than fooGVK back at fooGVK. Earlier versions of the
+// controller wrote "App" as the owner kind, which the garbage collector can't
+// resolve. It returns nil when there is nothing to fix.
+func fixOwnerReferences(refs []metav1.OwnerReference) []metav1.OwnerReference {
+	var fixed []metav1.OwnerReference
+	for i, ref := range refs {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil || gv.Group != fooGVK.Group || ref.Kind == fooGVK.Kind {
+			continue
+		}
+		if fixed == nil {
+			fixed = append([]metav1.OwnerReference(nil), refs...)
+		}
+		fixed[i].APIVersion = fooGVK.GroupVersion().String()
+		fixed[i].Kind = fooGVK.Kind
+	}
+	return fixed
+}
+
+// migrateOwnerReferences rewrites the owner references of the Deployments,
+// Services and Ingresses in the informer caches that name the wrong kind for
+// a App. It runs once the caches have synced, before the workers start.
+func (c *Controller) migrateOwnerReferences(ctx context.Context) error {
+	var errs []error
+
+	deployments, err := c.deploymentsLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments {
+		refs := fixOwnerReferences(deployment.OwnerReferences)
+		if refs == nil {
+			continue
+		}
+		klog.Infof("Fixing owner references of deployment '%s/%s'", deployment.Namespace, deployment.Name)
+		deploymentCopy := deployment.DeepCopy()
+		deploymentCopy.OwnerReferences = refs
+		_, err = c.kubeclientset.AppsV1().Deployments(deployment.Namespace).Update(ctx, deploymentCopy, metav1.UpdateOptions{})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		recordChildOperation("Deployment", operationUpdate)
+	}
+
+	services, err := c.serviceLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, service := range services {
+		refs := fixOwnerReferences(service.OwnerReferences)
+		if refs == nil {
+			continue
+		}
+		klog.Infof("Fixing owner references of service '%s/%s'", service.Namespace, service.Name)
+		serviceCopy := service.DeepCopy()
+		serviceCopy.OwnerReferences = refs
+		_, err = c.kubeclientset.CoreV1().Services(service.Namespace).Update(ctx, serviceCopy, metav1.UpdateOptions{})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		recordChildOperation("Service", operationUpdate)
+	}
+
+	ingresses, err := c.ingressLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, ingress := range ingresses {
+		refs := fixOwnerReferences(ingress.OwnerReferences)
+		if refs == nil {
+			continue
+		}
+		klog.Infof("Fixing owner references of ingress '%s/%s'", ingress.Namespace, ingress.Name)
+		ingressCopy := ingress.DeepCopy()
+		ingressCopy.OwnerReferences = refs
+		_, err = c.kubeclientset.NetworkingV1().Ingresses(ingress.Namespace).Update(ctx, ingressCopy, metav1.UpdateOptions{})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		recordChildOperation("Ingress", operationUpdate)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// labelChildren adds the labels of their App to the Deployments,
+// HorizontalPodAutoscalers, Services and Ingresses of namespace controlled by
+// an App matching labelSelector, when they don't match it themselves. The
+// informers of the children only watch the ones matching labelSelector, so
+// children created before the labels of their App were propagated to them
+// would be invisible to the controller. It reads from the API server, and
+// runs once before the informers are started.
+func labelChildren(ctx context.Context, kubeClient kubernetes.Interface, groupKindClient clientset.Interface, namespace, labelSelector string) error {
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return err
+	}
+	foos, err := groupKindClient.GroupkindV1alpha1().Foos(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return err
+	}
+	owners := make(map[types.UID]*groupkindv1alpha1.Foo, len(foos.Items))
+	for i := range foos.Items {
+		owners[foos.Items[i].UID] = &foos.Items[i]
+	}
+	// owner returns the App controlling a child that doesn't match the
+	// selector, or nil.
+	owner := func(obj metav1.Object) *groupkindv1alpha1.Foo {
+		ref := metav1.GetControllerOf(obj)
+		if ref == nil || selector.Matches(labels.Set(obj.GetLabels())) {
+			return nil
+		}
+		return owners[ref.UID]
+	}
+	var errs []error
+
+	deployments, err := kubeClient.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		foo := owner(deployment)
+		if foo == nil {
+			continue
+		}
+		klog.Infof("Labelling deployment '%s/%s' of app '%s'", deployment.Namespace, deployment.Name, foo.Name)
+		deployment.Labels = mergeStringMap(deployment.Labels, childLabels(foo))
+		_, err = kubeClient.AppsV1().Deployments(deployment.Namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		recordChildOperation("Deployment", operationUpdate)
+	}
+
+	hpas, err := kubeClient.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range hpas.Items {
+		hpa := &hpas.Items[i]
+		foo := owner(hpa)
+		if foo == nil {
+			continue
+		}
+		klog.Infof("Labelling horizontal pod autoscaler '%s/%s' of app '%s'", hpa.Namespace, hpa.Name, foo.Name)
+		hpa.Labels = mergeStringMap(hpa.Labels, childLabels(foo))
+		_, err = kubeClient.AutoscalingV2().HorizontalPodAutoscalers(hpa.Namespace).Update(ctx, hpa, metav1.UpdateOptions{})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		recordChildOperation("HorizontalPodAutoscaler", operationUpdate)
+	}
+
+	services, err := kubeClient.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range services.Items {
+		service := &services.Items[i]
+		foo := owner(service)
+		if foo == nil {
+			continue
+		}
+		klog.Infof("Labelling service '%s/%s' of app '%s'", service.Namespace, service.Name, foo.Name)
+		service.Labels = mergeStringMap(service.Labels, childLabels(foo))
+		_, err = kubeClient.CoreV1().Services(service.Namespace).Update(ctx, service, metav1.UpdateOptions{})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		recordChildOperation("Service", operationUpdate)
+	}
+
+	ingresses, err := kubeClient.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range ingresses.Items {
+		ingress := &ingresses.Items[i]
+		foo := owner(ingress)
+		if foo == nil {
+			continue
+		}
+		klog.Infof("Labelling ingress '%s/%s' of app '%s'", ingress.Namespace, ingress.Name, foo.Name)
+		ingress.Labels = mergeStringMap(ingress.Labels, childLabels(foo))
+		_, err = kubeClient.NetworkingV1().Ingresses(ingress.Namespace).Update(ctx, ingress, metav1.UpdateOptions{})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		recordChildOperation("Ingress", operationUpdate)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	groupkindv1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFooGVK(t *testing.T) {
+	expected := groupkindv1alpha1.SchemeGroupVersion.WithKind("Foo")
+	if fooGVK != expected {
+		t.Errorf("expected GroupVersionKind %s, got %s", expected, fooGVK)
+	}
+
+	ref := newDeployment(newFoo("test", 1)).OwnerReferences[0]
+	if ref.Kind != "Foo" || ref.APIVersion != expected.GroupVersion().String() {
+		t.Errorf("expected owner reference to %s, got %s %s", expected, ref.APIVersion, ref.Kind)
+	}
+}
+
+func TestMigrateOwnerReferences(t *testing.T) {
+	f := newFixture(t)
+	foo := withServiceAndIngress(newFoo("test", 1))
+	d := newDeployment(foo)
+	d.OwnerReferences[0].Kind = "App"
+	s := newService(foo)
+	ing := newIngress(foo)
+	ing.OwnerReferences[0].Kind = "App"
+	// References to other API groups are left alone.
+	ing.OwnerReferences = append(ing.OwnerReferences, metav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Name:       "config",
+		UID:        "config-uid",
+	})
+
+	f.fooLister = append(f.fooLister, foo)
+	f.objects = append(f.objects, foo)
+	f.deploymentLister = append(f.deploymentLister, d)
+	f.serviceLister = append(f.serviceLister, s)
+	f.ingressLister = append(f.ingressLister, ing)
+	f.kubeobjects = append(f.kubeobjects, d, s, ing)
+
+	expDeployment := d.DeepCopy()
+	expDeployment.OwnerReferences[0].Kind = "Foo"
+	expIngress := ing.DeepCopy()
+	expIngress.OwnerReferences[0].Kind = "Foo"
+	f.expectUpdateDeploymentAction(expDeployment)
+	f.expectUpdateIngressAction(expIngress)
+
+	c, _, _ := f.newController()
+	if err := c.migrateOwnerReferences(context.TODO()); err != nil {
+		t.Errorf("unexpected error migrating owner references: %v", err)
+	}
+	f.checkActions()
+}
+
+func TestLabelChildren(t *testing.T) {
+	f := newFixture(t)
+	foo := withServiceAndIngress(newFoo("test", 1))
+	foo.Labels = map[string]string{"team": "web"}
+	d := newDeployment(foo)
+	d.Labels = nil
+	s := newService(foo)
+	ing := newIngress(foo)
+	ing.Labels = map[string]string{"tier": "frontend"}
+	// The children of Apps outside the selector are left alone.
+	other := withServiceAndIngress(newFoo("other", 1))
+	otherIngress := newIngress(other)
+
+	f.objects = append(f.objects, foo, other)
+	f.kubeobjects = append(f.kubeobjects, d, s, ing, otherIngress)
+
+	expDeployment := d.DeepCopy()
+	expDeployment.Labels = map[string]string{"team": "web"}
+	expIngress := ing.DeepCopy()
+	expIngress.Labels = map[string]string{"team": "web", "tier": "frontend"}
+	f.expectUpdateDeploymentAction(expDeployment)
+	f.expectUpdateIngressAction(expIngress)
+
+	f.newController()
+	if err := labelChildren(context.TODO(), f.kubeclient, f.client, metav1.NamespaceAll, "team=web"); err != nil {
+		t.Errorf("unexpected error labelling children: %v", err)
+	}
+	f.checkActions()
+}
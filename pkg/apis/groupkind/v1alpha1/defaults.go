@@ -0,0 +1,116 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// NameLabel is the label a Foo is given with its name as the value when it
+// doesn't set it. Children carry the labels of their Foo, so it groups a Foo
+// with its Deployment, Service and Ingress.
+const NameLabel = "app.kubernetes.io/name"
+
+func init() {
+	localSchemeBuilder.Register(addDefaultingFuncs)
+}
+
+func addDefaultingFuncs(scheme *runtime.Scheme) error {
+	return RegisterDefaults(scheme)
+}
+
+// SetDefaults_Foo defaults the names of the children of a Foo: the
+// Deployment is named after the Foo, and the Service and Ingress after the
+// Deployment.
+func SetDefaults_Foo(obj *Foo) {
+	if obj.Labels[NameLabel] == "" {
+		if obj.Labels == nil {
+			obj.Labels = map[string]string{}
+		}
+		obj.Labels[NameLabel] = obj.Name
+	}
+	if obj.Spec.Deployment.Name == "" {
+		obj.Spec.Deployment.Name = obj.Name
+	}
+	if obj.Spec.Service != nil && obj.Spec.Service.Name == "" {
+		obj.Spec.Service.Name = obj.Spec.Deployment.Name
+	}
+	if obj.Spec.Ingress != nil && obj.Spec.Ingress.Name == "" {
+		obj.Spec.Ingress.Name = obj.Spec.Deployment.Name
+	}
+}
+
+func SetDefaults_DeploymentSpec(obj *DeploymentSpec) {
+	if obj.Replicas == nil {
+		replicas := int32(1)
+		obj.Replicas = &replicas
+	}
+}
+
+func SetDefaults_ServiceSpec(obj *ServiceSpec) {
+	if obj.Type == "" {
+		obj.Type = ServiceTypeClusterIP
+	}
+	if obj.SessionAffinity == "" {
+		obj.SessionAffinity = corev1.ServiceAffinityNone
+	}
+	if len(obj.Ports) == 0 {
+		obj.Ports = []ServicePort{{Port: 80}}
+	}
+}
+
+func SetDefaults_ServicePort(obj *ServicePort) {
+	if obj.Protocol == "" {
+		obj.Protocol = corev1.ProtocolTCP
+	}
+	if obj.TargetPort.Type == intstr.Int && obj.TargetPort.IntVal == 0 {
+		obj.TargetPort = intstr.FromInt(int(obj.Port))
+	}
+}
+
+func SetDefaults_IngressSpec(obj *IngressSpec) {
+	if len(obj.Rules) == 0 {
+		obj.Rules = []IngressRule{{Paths: []IngressPath{{}}}}
+	}
+}
+
+func SetDefaults_IngressPath(obj *IngressPath) {
+	if obj.Path == "" {
+		obj.Path = "/"
+	}
+	if obj.PathType == nil {
+		pathType := networkingv1.PathTypePrefix
+		obj.PathType = &pathType
+	}
+}
+
+// SetDefaults_AutoscalingSpec scales from a single pod, on the CPU usage of
+// the pods when no target is set, like a HorizontalPodAutoscaler without
+// metrics would.
+func SetDefaults_AutoscalingSpec(obj *AutoscalingSpec) {
+	if obj.MinReplicas == nil {
+		minReplicas := int32(1)
+		obj.MinReplicas = &minReplicas
+	}
+	if obj.TargetCPUUtilizationPercentage == nil && obj.TargetMemoryUtilizationPercentage == nil && len(obj.CustomMetrics) == 0 {
+		cpu := int32(80)
+		obj.TargetCPUUtilizationPercentage = &cpu
+	}
+}
@@ -15,6 +15,7 @@ limitations under the License.
 */
 
 // +k8s:deepcopy-gen=package
+// +k8s:defaulter-gen=TypeMeta
 // +groupName=groupkind.k8s.io
 
 // Package v1alpha1 is the v1alpha1 version of the API.
@@ -17,48 +17,332 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.deployment.replicas,statuspath=.status.replicas,selectorpath=.status.selector
+// +kubebuilder:printcolumn:name="Deployment",type=string,JSONPath=`.spec.deployment.name`
+// +kubebuilder:printcolumn:name="Replicas",type=integer,JSONPath=`.spec.deployment.replicas`
+// +kubebuilder:printcolumn:name="Available",type=integer,JSONPath=`.status.availableReplicas`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Foo is a specification for a Foo resource
 type Foo struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec   FooSpec   `json:"spec"`
+	Spec FooSpec `json:"spec"`
+	// +optional
 	Status FooStatus `json:"status"`
 }
 
 type DeploymentSpec struct {
-	Name     string `json:"name"`
-	Image    string `json:"image"`
-	Replicas int32  `json:"replicas"`
-	//add new field
+	// Name of the Deployment and of its container. Defaults to the name of
+	// the Foo.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Image is the container image reference, e.g.
+	// registry.example.com:5000/team/app:v1 or app@sha256:<digest>.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]+([._-][a-z0-9]+)*(:[0-9]+)?(/[a-z0-9]+([._-][a-z0-9]+)*)*(:[A-Za-z0-9_][A-Za-z0-9_.-]{0,127})?(@sha256:[a-f0-9]{64})?$`
+	Image string `json:"image"`
+	// Replicas is the number of pods of the Deployment. Defaults to 1.
+	// Ignored while autoscaling is enabled.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=1
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Command overrides the entrypoint of the image.
+	Command []string `json:"command,omitempty"`
+	// Args are the arguments to the entrypoint.
+	Args []string `json:"args,omitempty"`
+	// Env lists the environment variables of the container, including ones
+	// read from secrets and config maps through valueFrom.
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// Ports lists the ports exposed by the container.
+	Ports []corev1.ContainerPort `json:"ports,omitempty"`
+	// Resources are the compute resource requests and limits of the
+	// container.
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// LivenessProbe restarts the container when it fails.
+	LivenessProbe *corev1.Probe `json:"livenessProbe,omitempty"`
+	// ReadinessProbe removes the pod from service endpoints when it fails.
+	ReadinessProbe *corev1.Probe `json:"readinessProbe,omitempty"`
+	// StartupProbe holds off the other probes until it succeeds.
+	StartupProbe *corev1.Probe `json:"startupProbe,omitempty"`
+	// ImagePullPolicy is the pull policy of the image. Left to the API server
+	// default when empty.
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// ImagePullSecrets reference the secrets used to pull the image.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 }
 
 type ServiceSpec struct {
+	// Name of the Service, defaulting to the name of the Deployment.
+	// Changing it replaces the Service with one of the new name.
+	// +optional
 	Name string `json:"name"`
+	// Enabled defaults to true. Setting it to false deletes the Service while
+	// keeping its configuration in the spec.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Type is how the Service is exposed. Defaults to ClusterIP.
+	Type ServiceType `json:"type,omitempty"`
+	// Ports lists the ports of the Service. Defaults to a single TCP port 80.
+	Ports []ServicePort `json:"ports,omitempty"`
+	// SessionAffinity is either None or ClientIP. Defaults to None.
+	// +kubebuilder:validation:Enum=None;ClientIP
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
+	// Annotations are added to the Service.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ServiceType is how the Service of a Foo is exposed.
+// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer;Headless
+type ServiceType string
+
+const (
+	ServiceTypeClusterIP    ServiceType = "ClusterIP"
+	ServiceTypeNodePort     ServiceType = "NodePort"
+	ServiceTypeLoadBalancer ServiceType = "LoadBalancer"
+	// ServiceTypeHeadless is a ClusterIP Service without a cluster IP.
+	ServiceTypeHeadless ServiceType = "Headless"
+)
+
+// ServicePort is a port of the Service of a Foo.
+type ServicePort struct {
+	// Name is required when the Service has more than one port.
+	Name string `json:"name,omitempty"`
+	// Protocol defaults to TCP.
+	// +kubebuilder:validation:Enum=TCP;UDP;SCTP
+	Protocol corev1.Protocol `json:"protocol,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+	// TargetPort is the number or name of the container port traffic is
+	// sent to. Defaults to Port.
+	TargetPort intstr.IntOrString `json:"targetPort,omitempty"`
+	// NodePort is the port on each node for NodePort and LoadBalancer
+	// Services. Allocated by the API server when not set.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	NodePort int32 `json:"nodePort,omitempty"`
 }
 
 type IngressSpec struct {
+	// Name of the Ingress, defaulting to the name of the Deployment.
+	// Changing it replaces the Ingress with one of the new name.
+	// +optional
 	Name string `json:"name"`
+	// Enabled defaults to true. Setting it to false deletes the Ingress while
+	// keeping its configuration in the spec. An Ingress needs the Service,
+	// so it is disabled as well when the Service is.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// IngressClassName selects the ingress controller. Left to the cluster
+	// default when not set.
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+	// Rules route hosts and paths to the Service. Defaults to a single rule
+	// routing "/" on any host to the first Service port.
+	Rules []IngressRule `json:"rules,omitempty"`
+	// TLS configures TLS termination with certificates read from secrets.
+	TLS []networkingv1.IngressTLS `json:"tls,omitempty"`
+	// Annotations are added to the Ingress, e.g. rewrite rules for the
+	// ingress controller.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// IngressRule routes the paths of a host to the Service of a Foo.
+type IngressRule struct {
+	// Host is the fully qualified domain name matched by the rule. An empty
+	// host matches every host.
+	Host string `json:"host,omitempty"`
+	// +kubebuilder:validation:MinItems=1
+	Paths []IngressPath `json:"paths"`
+}
+
+// IngressPath routes a path to a port of the Service of a Foo.
+type IngressPath struct {
+	// Path defaults to "/".
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path,omitempty"`
+	// PathType defaults to Prefix.
+	PathType *networkingv1.PathType `json:"pathType,omitempty"`
+	// Port is the name or number of the Service port traffic is sent to.
+	// Defaults to the first Service port.
+	Port intstr.IntOrString `json:"port,omitempty"`
+}
+
+// TeardownSpec opts a Foo into an ordered teardown of its children when it
+// is deleted, instead of relying on garbage collection alone.
+type TeardownSpec struct {
+	// TimeoutSeconds bounds how long the deletion of the Foo is blocked on
+	// the teardown. Defaults to 300.
+	// +kubebuilder:validation:Minimum=0
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// AutoscalingSpec scales the Deployment of a Foo with a
+// HorizontalPodAutoscaler, between MinReplicas and MaxReplicas, to meet its
+// targets.
+type AutoscalingSpec struct {
+	// Enabled defaults to true. Setting it to false deletes the
+	// HorizontalPodAutoscaler while keeping its configuration in the spec,
+	// and the Deployment goes back to Replicas.
+	Enabled *bool `json:"enabled,omitempty"`
+	// MinReplicas is the lower bound of the number of pods. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas is the upper bound of the number of pods. It can't be
+	// lower than MinReplicas.
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+	// TargetCPUUtilizationPercentage is the average CPU usage of the pods
+	// to scale to, as a percentage of their CPU request. Defaults to 80
+	// when no other target is set.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+	// TargetMemoryUtilizationPercentage is the average memory usage of the
+	// pods to scale to, as a percentage of their memory request.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+	// CustomMetrics are targets for metrics of the pods served by the
+	// custom metrics API.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	CustomMetrics []CustomMetricTarget `json:"customMetrics,omitempty"`
+}
+
+// CustomMetricTarget is the value to scale to of a metric of the pods of a
+// Foo, served by the custom metrics API.
+type CustomMetricTarget struct {
+	// Name of the metric.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Selector narrows down the series of the metric. When not set, only
+	// the metric name is used to collect it.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// AverageValue is the value of the metric, averaged over the pods, to
+	// scale to.
+	AverageValue resource.Quantity `json:"averageValue"`
 }
 
 // FooSpec is the spec for a Foo resource
 type FooSpec struct {
 	Deployment DeploymentSpec `json:"deployment"`
-	Service    ServiceSpec    `json:"service"`
-	Ingress    IngressSpec    `json:"ingress"`
+	// Service is optional, internal workers don't need one.
+	Service *ServiceSpec `json:"service,omitempty"`
+	// Ingress is optional, and requires Service.
+	Ingress  *IngressSpec  `json:"ingress,omitempty"`
+	Teardown *TeardownSpec `json:"teardown,omitempty"`
+	// Autoscaling is optional. While it is enabled, the number of pods of
+	// the Deployment is left to its HorizontalPodAutoscaler.
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
 }
 
+const (
+	// TeardownFinalizer is added to a Foo with a teardown spec, and removed
+	// once its children have been torn down.
+	TeardownFinalizer = "groupkind.k8s.io/teardown"
+	// ForceDeleteAnnotation, when set to "true" on a Foo being deleted, skips
+	// the rest of the teardown and removes the finalizer.
+	ForceDeleteAnnotation = "groupkind.k8s.io/force-delete"
+	// DefaultTeardownTimeoutSeconds is the teardown timeout used when
+	// TeardownSpec.TimeoutSeconds is not set.
+	DefaultTeardownTimeoutSeconds = 300
+)
+
 // FooStatus is the status for a Foo resource
 type FooStatus struct {
+	// Replicas is the number of pods targeted by the Deployment.
+	Replicas int32 `json:"replicas,omitempty"`
+	// Selector is the label selector of the pods of the Deployment, in
+	// string form, for the scale subresource.
+	Selector string `json:"selector,omitempty"`
+	// AvailableReplicas is the number of available pods of the Deployment.
+	// +optional
 	AvailableReplicas int32 `json:"availableReplicas"`
+	// ReadyReplicas is the number of ready pods of the Deployment.
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+	// UpdatedReplicas is the number of pods running the latest pod template.
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+	// ServiceClusterIP is the cluster IP allocated to the Service.
+	ServiceClusterIP string `json:"serviceClusterIP,omitempty"`
+	// IngressAddresses are the IPs or hostnames the Ingress load balancer is
+	// reachable at.
+	IngressAddresses []string `json:"ingressAddresses,omitempty"`
+	// Autoscaling is the state of the HorizontalPodAutoscaler of the Foo,
+	// while autoscaling is enabled.
+	Autoscaling *AutoscalingStatus `json:"autoscaling,omitempty"`
+	// ObservedGeneration is the most recent generation of the Foo acted on
+	// by the controller.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the current state of the Foo and its children.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// AutoscalingStatus is the state of the HorizontalPodAutoscaler of a Foo.
+type AutoscalingStatus struct {
+	// CurrentReplicas is the number of pods last seen by the autoscaler.
+	CurrentReplicas int32 `json:"currentReplicas,omitempty"`
+	// DesiredReplicas is the number of pods last computed by the
+	// autoscaler.
+	DesiredReplicas int32 `json:"desiredReplicas,omitempty"`
+	// LastScaleTime is the last time the autoscaler changed the number of
+	// pods.
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+	// CurrentCPUUtilizationPercentage is the average CPU usage of the pods,
+	// as a percentage of their CPU request, when CPU is a target.
+	// +optional
+	CurrentCPUUtilizationPercentage *int32 `json:"currentCPUUtilizationPercentage,omitempty"`
+	// CurrentMemoryUtilizationPercentage is the average memory usage of the
+	// pods, as a percentage of their memory request, when memory is a
+	// target.
+	// +optional
+	CurrentMemoryUtilizationPercentage *int32 `json:"currentMemoryUtilizationPercentage,omitempty"`
 }
 
+// These are the condition types set on a Foo.
+const (
+	// FooReady means every child of the Foo is reconciled and available.
+	FooReady = "Ready"
+	// FooDeploymentAvailable means the Deployment has its minimum number of
+	// available pods.
+	FooDeploymentAvailable = "DeploymentAvailable"
+	// FooServiceReady means the Service is reconciled.
+	FooServiceReady = "ServiceReady"
+	// FooIngressReady means the Ingress is reconciled.
+	FooIngressReady = "IngressReady"
+	// FooProgressing means the Deployment is rolling out a new pod template.
+	FooProgressing = "Progressing"
+	// FooDegraded means the last sync of the Foo failed.
+	FooDegraded = "Degraded"
+	// FooScalingActive means the HorizontalPodAutoscaler is able to compute
+	// the number of pods from its metrics. It is only set while autoscaling
+	// is enabled.
+	FooScalingActive = "ScalingActive"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // FooList is a list of Foo resources
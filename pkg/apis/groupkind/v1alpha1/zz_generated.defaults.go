@@ -0,0 +1,97 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by defaulter-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// RegisterDefaults adds defaulters functions to the given scheme.
+// Public to allow building arbitrary schemes.
+// All generated defaulters are covering - they call all nested defaulters.
+func RegisterDefaults(scheme *runtime.Scheme) error {
+	scheme.AddTypeDefaultingFunc(&Foo{}, func(obj interface{}) { SetObjectDefaults_Foo(obj.(*Foo)) })
+	scheme.AddTypeDefaultingFunc(&FooList{}, func(obj interface{}) { SetObjectDefaults_FooList(obj.(*FooList)) })
+	return nil
+}
+
+func SetObjectDefaults_Foo(in *Foo) {
+	SetDefaults_Foo(in)
+	SetDefaults_DeploymentSpec(&in.Spec.Deployment)
+	for i := range in.Spec.Deployment.Ports {
+		a := &in.Spec.Deployment.Ports[i]
+		if a.Protocol == "" {
+			a.Protocol = "TCP"
+		}
+	}
+	if in.Spec.Deployment.LivenessProbe != nil {
+		if in.Spec.Deployment.LivenessProbe.ProbeHandler.GRPC != nil {
+			if in.Spec.Deployment.LivenessProbe.ProbeHandler.GRPC.Service == nil {
+				var ptrVar1 string = ""
+				in.Spec.Deployment.LivenessProbe.ProbeHandler.GRPC.Service = &ptrVar1
+			}
+		}
+	}
+	if in.Spec.Deployment.ReadinessProbe != nil {
+		if in.Spec.Deployment.ReadinessProbe.ProbeHandler.GRPC != nil {
+			if in.Spec.Deployment.ReadinessProbe.ProbeHandler.GRPC.Service == nil {
+				var ptrVar1 string = ""
+				in.Spec.Deployment.ReadinessProbe.ProbeHandler.GRPC.Service = &ptrVar1
+			}
+		}
+	}
+	if in.Spec.Deployment.StartupProbe != nil {
+		if in.Spec.Deployment.StartupProbe.ProbeHandler.GRPC != nil {
+			if in.Spec.Deployment.StartupProbe.ProbeHandler.GRPC.Service == nil {
+				var ptrVar1 string = ""
+				in.Spec.Deployment.StartupProbe.ProbeHandler.GRPC.Service = &ptrVar1
+			}
+		}
+	}
+	if in.Spec.Service != nil {
+		SetDefaults_ServiceSpec(in.Spec.Service)
+		for i := range in.Spec.Service.Ports {
+			a := &in.Spec.Service.Ports[i]
+			SetDefaults_ServicePort(a)
+		}
+	}
+	if in.Spec.Ingress != nil {
+		SetDefaults_IngressSpec(in.Spec.Ingress)
+		for i := range in.Spec.Ingress.Rules {
+			a := &in.Spec.Ingress.Rules[i]
+			for j := range a.Paths {
+				b := &a.Paths[j]
+				SetDefaults_IngressPath(b)
+			}
+		}
+	}
+	if in.Spec.Autoscaling != nil {
+		SetDefaults_AutoscalingSpec(in.Spec.Autoscaling)
+	}
+}
+
+func SetObjectDefaults_FooList(in *FooList) {
+	for i := range in.Items {
+		a := &in.Items[i]
+		SetObjectDefaults_Foo(a)
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"controller-crd/pkg/apis/groupkind/v1alpha1"
+)
+
+// ConvertTo converts this Foo to the hub version, v1alpha1.
+func (src *Foo) ConvertTo(dst *v1alpha1.Foo) error {
+	src = src.DeepCopy()
+	dst.TypeMeta = src.TypeMeta
+	dst.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1alpha1.FooSpec{
+		Deployment: v1alpha1.DeploymentSpec(src.Spec.Workload),
+		Teardown:   (*v1alpha1.TeardownSpec)(src.Spec.Teardown),
+	}
+	if a := src.Spec.Autoscaling; a != nil {
+		dst.Spec.Autoscaling = &v1alpha1.AutoscalingSpec{
+			Enabled:                           a.Enabled,
+			MinReplicas:                       a.MinReplicas,
+			MaxReplicas:                       a.MaxReplicas,
+			TargetCPUUtilizationPercentage:    a.TargetCPUUtilizationPercentage,
+			TargetMemoryUtilizationPercentage: a.TargetMemoryUtilizationPercentage,
+		}
+		for _, m := range a.CustomMetrics {
+			dst.Spec.Autoscaling.CustomMetrics = append(dst.Spec.Autoscaling.CustomMetrics, v1alpha1.CustomMetricTarget(m))
+		}
+	}
+	if s := src.Spec.Service; s != nil {
+		dst.Spec.Service = &v1alpha1.ServiceSpec{
+			Name:            s.Name,
+			Enabled:         s.Enabled,
+			Type:            v1alpha1.ServiceType(s.Type),
+			SessionAffinity: s.SessionAffinity,
+			Annotations:     s.Annotations,
+		}
+		for _, p := range s.Ports {
+			dst.Spec.Service.Ports = append(dst.Spec.Service.Ports, v1alpha1.ServicePort(p))
+		}
+	}
+	if i := src.Spec.Ingress; i != nil {
+		dst.Spec.Ingress = &v1alpha1.IngressSpec{
+			Name:             i.Name,
+			Enabled:          i.Enabled,
+			IngressClassName: i.IngressClassName,
+			Rules:            rulesFromRoutes(i.Routes),
+			TLS:              i.TLS,
+			Annotations:      i.Annotations,
+		}
+	}
+	dst.Status = v1alpha1.FooStatus{
+		Replicas:           src.Status.Replicas,
+		Selector:           src.Status.Selector,
+		AvailableReplicas:  src.Status.AvailableReplicas,
+		ReadyReplicas:      src.Status.ReadyReplicas,
+		UpdatedReplicas:    src.Status.UpdatedReplicas,
+		ServiceClusterIP:   src.Status.ServiceClusterIP,
+		IngressAddresses:   src.Status.IngressAddresses,
+		Autoscaling:        (*v1alpha1.AutoscalingStatus)(src.Status.Autoscaling),
+		ObservedGeneration: src.Status.ObservedGeneration,
+		Conditions:         src.Status.Conditions,
+	}
+	return nil
+}
+
+// ConvertFrom converts a Foo of the hub version, v1alpha1, to this version.
+func (dst *Foo) ConvertFrom(src *v1alpha1.Foo) error {
+	src = src.DeepCopy()
+	dst.TypeMeta = src.TypeMeta
+	dst.APIVersion = SchemeGroupVersion.String()
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = FooSpec{
+		Workload: WorkloadSpec(src.Spec.Deployment),
+		Teardown: (*TeardownSpec)(src.Spec.Teardown),
+	}
+	if a := src.Spec.Autoscaling; a != nil {
+		dst.Spec.Autoscaling = &AutoscalingSpec{
+			Enabled:                           a.Enabled,
+			MinReplicas:                       a.MinReplicas,
+			MaxReplicas:                       a.MaxReplicas,
+			TargetCPUUtilizationPercentage:    a.TargetCPUUtilizationPercentage,
+			TargetMemoryUtilizationPercentage: a.TargetMemoryUtilizationPercentage,
+		}
+		for _, m := range a.CustomMetrics {
+			dst.Spec.Autoscaling.CustomMetrics = append(dst.Spec.Autoscaling.CustomMetrics, CustomMetricTarget(m))
+		}
+	}
+	if s := src.Spec.Service; s != nil {
+		dst.Spec.Service = &ServiceSpec{
+			Name:            s.Name,
+			Enabled:         s.Enabled,
+			Type:            ServiceType(s.Type),
+			SessionAffinity: s.SessionAffinity,
+			Annotations:     s.Annotations,
+		}
+		for _, p := range s.Ports {
+			dst.Spec.Service.Ports = append(dst.Spec.Service.Ports, ServicePort(p))
+		}
+	}
+	if i := src.Spec.Ingress; i != nil {
+		dst.Spec.Ingress = &IngressSpec{
+			Name:             i.Name,
+			Enabled:          i.Enabled,
+			IngressClassName: i.IngressClassName,
+			Routes:           routesFromRules(i.Rules),
+			TLS:              i.TLS,
+			Annotations:      i.Annotations,
+		}
+	}
+	dst.Status = FooStatus{
+		Replicas:           src.Status.Replicas,
+		Selector:           src.Status.Selector,
+		AvailableReplicas:  src.Status.AvailableReplicas,
+		ReadyReplicas:      src.Status.ReadyReplicas,
+		UpdatedReplicas:    src.Status.UpdatedReplicas,
+		ServiceClusterIP:   src.Status.ServiceClusterIP,
+		IngressAddresses:   src.Status.IngressAddresses,
+		Autoscaling:        (*AutoscalingStatus)(src.Status.Autoscaling),
+		ObservedGeneration: src.Status.ObservedGeneration,
+		Conditions:         src.Status.Conditions,
+	}
+	return nil
+}
+
+// rulesFromRoutes groups consecutive routes of the same host into a rule.
+func rulesFromRoutes(routes []Route) []v1alpha1.IngressRule {
+	var rules []v1alpha1.IngressRule
+	for _, r := range routes {
+		path := v1alpha1.IngressPath{Path: r.Path, PathType: r.PathType, Port: r.Port}
+		if n := len(rules); n > 0 && rules[n-1].Host == r.Host {
+			rules[n-1].Paths = append(rules[n-1].Paths, path)
+			continue
+		}
+		rules = append(rules, v1alpha1.IngressRule{Host: r.Host, Paths: []v1alpha1.IngressPath{path}})
+	}
+	return rules
+}
+
+// routesFromRules flattens the paths of rules into routes. Consecutive rules
+// of the same host come back from rulesFromRoutes as one rule, which routes
+// the same paths.
+func routesFromRules(rules []v1alpha1.IngressRule) []Route {
+	var routes []Route
+	for _, rule := range rules {
+		for _, p := range rule.Paths {
+			routes = append(routes, Route{Host: rule.Host, Path: p.Path, PathType: p.PathType, Port: p.Port})
+		}
+	}
+	return routes
+}
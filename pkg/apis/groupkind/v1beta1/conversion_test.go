@@ -0,0 +1,124 @@
+package v1beta1
+
+import (
+	"controller-crd/pkg/apis/groupkind/v1alpha1"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func newHubFoo() *v1alpha1.Foo {
+	replicas := int32(3)
+	timeout := int32(60)
+	enabled := true
+	exact := networkingv1.PathTypeExact
+	minReplicas := int32(2)
+	cpu := int32(70)
+	return &v1alpha1.Foo{
+		TypeMeta: metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "Foo"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: metav1.NamespaceDefault,
+			Labels:    map[string]string{"team": "a"},
+		},
+		Spec: v1alpha1.FooSpec{
+			Deployment: v1alpha1.DeploymentSpec{
+				Name:     "web",
+				Image:    "nginx:1.23",
+				Replicas: &replicas,
+				Env:      []corev1.EnvVar{{Name: "MODE", Value: "prod"}},
+				Ports:    []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+			},
+			Service: &v1alpha1.ServiceSpec{
+				Name:    "web",
+				Enabled: &enabled,
+				Type:    v1alpha1.ServiceTypeNodePort,
+				Ports: []v1alpha1.ServicePort{
+					{Name: "http", Port: 80, TargetPort: intstr.FromString("http")},
+					{Name: "metrics", Port: 9090, NodePort: 30090},
+				},
+				SessionAffinity: corev1.ServiceAffinityClientIP,
+			},
+			Ingress: &v1alpha1.IngressSpec{
+				Name: "web",
+				Rules: []v1alpha1.IngressRule{
+					{Host: "example.com", Paths: []v1alpha1.IngressPath{{Path: "/"}, {Path: "/api", PathType: &exact, Port: intstr.FromInt(9090)}}},
+					{Host: "example.org", Paths: []v1alpha1.IngressPath{{Path: "/"}}},
+				},
+				Annotations: map[string]string{"nginx.ingress.kubernetes.io/rewrite-target": "/"},
+			},
+			Teardown: &v1alpha1.TeardownSpec{TimeoutSeconds: &timeout},
+			Autoscaling: &v1alpha1.AutoscalingSpec{
+				MinReplicas:                    &minReplicas,
+				MaxReplicas:                    10,
+				TargetCPUUtilizationPercentage: &cpu,
+				CustomMetrics: []v1alpha1.CustomMetricTarget{
+					{Name: "requests_per_second", AverageValue: resource.MustParse("100")},
+				},
+			},
+		},
+		Status: v1alpha1.FooStatus{
+			Replicas:          3,
+			AvailableReplicas: 2,
+			Selector:          "app=web",
+			Autoscaling:       &v1alpha1.AutoscalingStatus{CurrentReplicas: 3, DesiredReplicas: 4, CurrentCPUUtilizationPercentage: &cpu},
+			Conditions:        []metav1.Condition{{Type: v1alpha1.FooReady, Status: metav1.ConditionFalse, Reason: "Progressing"}},
+		},
+	}
+}
+
+func TestConvertFromHub(t *testing.T) {
+	hub := newHubFoo()
+	spoke := &Foo{}
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatal(err)
+	}
+	if spoke.APIVersion != SchemeGroupVersion.String() || spoke.Kind != "Foo" {
+		t.Errorf("expected a %s Foo, got %s %s", SchemeGroupVersion, spoke.APIVersion, spoke.Kind)
+	}
+	if spoke.Spec.Workload.Name != "web" || *spoke.Spec.Workload.Replicas != 3 {
+		t.Errorf("expected the deployment to become the workload, got %#v", spoke.Spec.Workload)
+	}
+	expRoutes := []Route{
+		{Host: "example.com", Path: "/"},
+		{Host: "example.com", Path: "/api", PathType: hub.Spec.Ingress.Rules[0].Paths[1].PathType, Port: intstr.FromInt(9090)},
+		{Host: "example.org", Path: "/"},
+	}
+	if !equality.Semantic.DeepEqual(spoke.Spec.Ingress.Routes, expRoutes) {
+		t.Errorf("expected routes %#v, got %#v", expRoutes, spoke.Spec.Ingress.Routes)
+	}
+
+	// The hub must not share memory with the converted Foo.
+	*spoke.Spec.Workload.Replicas = 5
+	if *hub.Spec.Deployment.Replicas != 3 {
+		t.Errorf("expected the hub to be left unchanged")
+	}
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	hub := newHubFoo()
+	spoke := &Foo{}
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatal(err)
+	}
+	back := &v1alpha1.Foo{}
+	if err := spoke.ConvertTo(back); err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(hub, back) {
+		t.Errorf("expected the round trip through %s to be lossless:\n%#v\n%#v", SchemeGroupVersion, hub, back)
+	}
+
+	again := &Foo{}
+	if err := again.ConvertFrom(back); err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(spoke, again) {
+		t.Errorf("expected the round trip through the hub to be lossless:\n%#v\n%#v", spoke, again)
+	}
+}
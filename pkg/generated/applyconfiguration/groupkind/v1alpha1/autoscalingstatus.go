@@ -0,0 +1,79 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AutoscalingStatusApplyConfiguration represents an declarative configuration of the AutoscalingStatus type for use
+// with apply.
+type AutoscalingStatusApplyConfiguration struct {
+	CurrentReplicas                    *int32   `json:"currentReplicas,omitempty"`
+	DesiredReplicas                    *int32   `json:"desiredReplicas,omitempty"`
+	LastScaleTime                      *v1.Time `json:"lastScaleTime,omitempty"`
+	CurrentCPUUtilizationPercentage    *int32   `json:"currentCPUUtilizationPercentage,omitempty"`
+	CurrentMemoryUtilizationPercentage *int32   `json:"currentMemoryUtilizationPercentage,omitempty"`
+}
+
+// AutoscalingStatusApplyConfiguration constructs an declarative configuration of the AutoscalingStatus type for use with
+// apply.
+func AutoscalingStatus() *AutoscalingStatusApplyConfiguration {
+	return &AutoscalingStatusApplyConfiguration{}
+}
+
+// WithCurrentReplicas sets the CurrentReplicas field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CurrentReplicas field is set to the value of the last call.
+func (b *AutoscalingStatusApplyConfiguration) WithCurrentReplicas(value int32) *AutoscalingStatusApplyConfiguration {
+	b.CurrentReplicas = &value
+	return b
+}
+
+// WithDesiredReplicas sets the DesiredReplicas field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DesiredReplicas field is set to the value of the last call.
+func (b *AutoscalingStatusApplyConfiguration) WithDesiredReplicas(value int32) *AutoscalingStatusApplyConfiguration {
+	b.DesiredReplicas = &value
+	return b
+}
+
+// WithLastScaleTime sets the LastScaleTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastScaleTime field is set to the value of the last call.
+func (b *AutoscalingStatusApplyConfiguration) WithLastScaleTime(value v1.Time) *AutoscalingStatusApplyConfiguration {
+	b.LastScaleTime = &value
+	return b
+}
+
+// WithCurrentCPUUtilizationPercentage sets the CurrentCPUUtilizationPercentage field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CurrentCPUUtilizationPercentage field is set to the value of the last call.
+func (b *AutoscalingStatusApplyConfiguration) WithCurrentCPUUtilizationPercentage(value int32) *AutoscalingStatusApplyConfiguration {
+	b.CurrentCPUUtilizationPercentage = &value
+	return b
+}
+
+// WithCurrentMemoryUtilizationPercentage sets the CurrentMemoryUtilizationPercentage field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CurrentMemoryUtilizationPercentage field is set to the value of the last call.
+func (b *AutoscalingStatusApplyConfiguration) WithCurrentMemoryUtilizationPercentage(value int32) *AutoscalingStatusApplyConfiguration {
+	b.CurrentMemoryUtilizationPercentage = &value
+	return b
+}
@@ -0,0 +1,171 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/client-go/applyconfigurations/core/v1"
+)
+
+// DeploymentSpecApplyConfiguration represents an declarative configuration of the DeploymentSpec type for use
+// with apply.
+type DeploymentSpecApplyConfiguration struct {
+	Name             *string                                     `json:"name,omitempty"`
+	Image            *string                                     `json:"image,omitempty"`
+	Replicas         *int32                                      `json:"replicas,omitempty"`
+	Command          []string                                    `json:"command,omitempty"`
+	Args             []string                                    `json:"args,omitempty"`
+	Env              []v1.EnvVarApplyConfiguration               `json:"env,omitempty"`
+	Ports            []v1.ContainerPortApplyConfiguration        `json:"ports,omitempty"`
+	Resources        *v1.ResourceRequirementsApplyConfiguration  `json:"resources,omitempty"`
+	LivenessProbe    *v1.ProbeApplyConfiguration                 `json:"livenessProbe,omitempty"`
+	ReadinessProbe   *v1.ProbeApplyConfiguration                 `json:"readinessProbe,omitempty"`
+	StartupProbe     *v1.ProbeApplyConfiguration                 `json:"startupProbe,omitempty"`
+	ImagePullPolicy  *corev1.PullPolicy                          `json:"imagePullPolicy,omitempty"`
+	ImagePullSecrets []v1.LocalObjectReferenceApplyConfiguration `json:"imagePullSecrets,omitempty"`
+}
+
+// DeploymentSpecApplyConfiguration constructs an declarative configuration of the DeploymentSpec type for use with
+// apply.
+func DeploymentSpec() *DeploymentSpecApplyConfiguration {
+	return &DeploymentSpecApplyConfiguration{}
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *DeploymentSpecApplyConfiguration) WithName(value string) *DeploymentSpecApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithImage sets the Image field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Image field is set to the value of the last call.
+func (b *DeploymentSpecApplyConfiguration) WithImage(value string) *DeploymentSpecApplyConfiguration {
+	b.Image = &value
+	return b
+}
+
+// WithReplicas sets the Replicas field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Replicas field is set to the value of the last call.
+func (b *DeploymentSpecApplyConfiguration) WithReplicas(value int32) *DeploymentSpecApplyConfiguration {
+	b.Replicas = &value
+	return b
+}
+
+// WithCommand adds the given value to the Command field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Command field.
+func (b *DeploymentSpecApplyConfiguration) WithCommand(values ...string) *DeploymentSpecApplyConfiguration {
+	for i := range values {
+		b.Command = append(b.Command, values[i])
+	}
+	return b
+}
+
+// WithArgs adds the given value to the Args field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Args field.
+func (b *DeploymentSpecApplyConfiguration) WithArgs(values ...string) *DeploymentSpecApplyConfiguration {
+	for i := range values {
+		b.Args = append(b.Args, values[i])
+	}
+	return b
+}
+
+// WithEnv adds the given value to the Env field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Env field.
+func (b *DeploymentSpecApplyConfiguration) WithEnv(values ...*v1.EnvVarApplyConfiguration) *DeploymentSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithEnv")
+		}
+		b.Env = append(b.Env, *values[i])
+	}
+	return b
+}
+
+// WithPorts adds the given value to the Ports field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Ports field.
+func (b *DeploymentSpecApplyConfiguration) WithPorts(values ...*v1.ContainerPortApplyConfiguration) *DeploymentSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithPorts")
+		}
+		b.Ports = append(b.Ports, *values[i])
+	}
+	return b
+}
+
+// WithResources sets the Resources field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Resources field is set to the value of the last call.
+func (b *DeploymentSpecApplyConfiguration) WithResources(value *v1.ResourceRequirementsApplyConfiguration) *DeploymentSpecApplyConfiguration {
+	b.Resources = value
+	return b
+}
+
+// WithLivenessProbe sets the LivenessProbe field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LivenessProbe field is set to the value of the last call.
+func (b *DeploymentSpecApplyConfiguration) WithLivenessProbe(value *v1.ProbeApplyConfiguration) *DeploymentSpecApplyConfiguration {
+	b.LivenessProbe = value
+	return b
+}
+
+// WithReadinessProbe sets the ReadinessProbe field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ReadinessProbe field is set to the value of the last call.
+func (b *DeploymentSpecApplyConfiguration) WithReadinessProbe(value *v1.ProbeApplyConfiguration) *DeploymentSpecApplyConfiguration {
+	b.ReadinessProbe = value
+	return b
+}
+
+// WithStartupProbe sets the StartupProbe field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the StartupProbe field is set to the value of the last call.
+func (b *DeploymentSpecApplyConfiguration) WithStartupProbe(value *v1.ProbeApplyConfiguration) *DeploymentSpecApplyConfiguration {
+	b.StartupProbe = value
+	return b
+}
+
+// WithImagePullPolicy sets the ImagePullPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ImagePullPolicy field is set to the value of the last call.
+func (b *DeploymentSpecApplyConfiguration) WithImagePullPolicy(value corev1.PullPolicy) *DeploymentSpecApplyConfiguration {
+	b.ImagePullPolicy = &value
+	return b
+}
+
+// WithImagePullSecrets adds the given value to the ImagePullSecrets field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ImagePullSecrets field.
+func (b *DeploymentSpecApplyConfiguration) WithImagePullSecrets(values ...*v1.LocalObjectReferenceApplyConfiguration) *DeploymentSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithImagePullSecrets")
+		}
+		b.ImagePullSecrets = append(b.ImagePullSecrets, *values[i])
+	}
+	return b
+}
@@ -0,0 +1,75 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// FooSpecApplyConfiguration represents an declarative configuration of the FooSpec type for use
+// with apply.
+type FooSpecApplyConfiguration struct {
+	Deployment  *DeploymentSpecApplyConfiguration  `json:"deployment,omitempty"`
+	Service     *ServiceSpecApplyConfiguration     `json:"service,omitempty"`
+	Ingress     *IngressSpecApplyConfiguration     `json:"ingress,omitempty"`
+	Teardown    *TeardownSpecApplyConfiguration    `json:"teardown,omitempty"`
+	Autoscaling *AutoscalingSpecApplyConfiguration `json:"autoscaling,omitempty"`
+}
+
+// FooSpecApplyConfiguration constructs an declarative configuration of the FooSpec type for use with
+// apply.
+func FooSpec() *FooSpecApplyConfiguration {
+	return &FooSpecApplyConfiguration{}
+}
+
+// WithDeployment sets the Deployment field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Deployment field is set to the value of the last call.
+func (b *FooSpecApplyConfiguration) WithDeployment(value *DeploymentSpecApplyConfiguration) *FooSpecApplyConfiguration {
+	b.Deployment = value
+	return b
+}
+
+// WithService sets the Service field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Service field is set to the value of the last call.
+func (b *FooSpecApplyConfiguration) WithService(value *ServiceSpecApplyConfiguration) *FooSpecApplyConfiguration {
+	b.Service = value
+	return b
+}
+
+// WithIngress sets the Ingress field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Ingress field is set to the value of the last call.
+func (b *FooSpecApplyConfiguration) WithIngress(value *IngressSpecApplyConfiguration) *FooSpecApplyConfiguration {
+	b.Ingress = value
+	return b
+}
+
+// WithTeardown sets the Teardown field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Teardown field is set to the value of the last call.
+func (b *FooSpecApplyConfiguration) WithTeardown(value *TeardownSpecApplyConfiguration) *FooSpecApplyConfiguration {
+	b.Teardown = value
+	return b
+}
+
+// WithAutoscaling sets the Autoscaling field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Autoscaling field is set to the value of the last call.
+func (b *FooSpecApplyConfiguration) WithAutoscaling(value *AutoscalingSpecApplyConfiguration) *FooSpecApplyConfiguration {
+	b.Autoscaling = value
+	return b
+}
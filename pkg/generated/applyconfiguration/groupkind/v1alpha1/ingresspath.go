@@ -0,0 +1,62 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/networking/v1"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// IngressPathApplyConfiguration represents an declarative configuration of the IngressPath type for use
+// with apply.
+type IngressPathApplyConfiguration struct {
+	Path     *string             `json:"path,omitempty"`
+	PathType *v1.PathType        `json:"pathType,omitempty"`
+	Port     *intstr.IntOrString `json:"port,omitempty"`
+}
+
+// IngressPathApplyConfiguration constructs an declarative configuration of the IngressPath type for use with
+// apply.
+func IngressPath() *IngressPathApplyConfiguration {
+	return &IngressPathApplyConfiguration{}
+}
+
+// WithPath sets the Path field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Path field is set to the value of the last call.
+func (b *IngressPathApplyConfiguration) WithPath(value string) *IngressPathApplyConfiguration {
+	b.Path = &value
+	return b
+}
+
+// WithPathType sets the PathType field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PathType field is set to the value of the last call.
+func (b *IngressPathApplyConfiguration) WithPathType(value v1.PathType) *IngressPathApplyConfiguration {
+	b.PathType = &value
+	return b
+}
+
+// WithPort sets the Port field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Port field is set to the value of the last call.
+func (b *IngressPathApplyConfiguration) WithPort(value intstr.IntOrString) *IngressPathApplyConfiguration {
+	b.Port = &value
+	return b
+}
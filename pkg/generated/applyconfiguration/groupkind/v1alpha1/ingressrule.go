@@ -0,0 +1,53 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// IngressRuleApplyConfiguration represents an declarative configuration of the IngressRule type for use
+// with apply.
+type IngressRuleApplyConfiguration struct {
+	Host  *string                         `json:"host,omitempty"`
+	Paths []IngressPathApplyConfiguration `json:"paths,omitempty"`
+}
+
+// IngressRuleApplyConfiguration constructs an declarative configuration of the IngressRule type for use with
+// apply.
+func IngressRule() *IngressRuleApplyConfiguration {
+	return &IngressRuleApplyConfiguration{}
+}
+
+// WithHost sets the Host field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Host field is set to the value of the last call.
+func (b *IngressRuleApplyConfiguration) WithHost(value string) *IngressRuleApplyConfiguration {
+	b.Host = &value
+	return b
+}
+
+// WithPaths adds the given value to the Paths field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Paths field.
+func (b *IngressRuleApplyConfiguration) WithPaths(values ...*IngressPathApplyConfiguration) *IngressRuleApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithPaths")
+		}
+		b.Paths = append(b.Paths, *values[i])
+	}
+	return b
+}
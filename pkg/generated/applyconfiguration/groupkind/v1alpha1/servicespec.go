@@ -0,0 +1,101 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// ServiceSpecApplyConfiguration represents an declarative configuration of the ServiceSpec type for use
+// with apply.
+type ServiceSpecApplyConfiguration struct {
+	Name            *string                         `json:"name,omitempty"`
+	Enabled         *bool                           `json:"enabled,omitempty"`
+	Type            *v1alpha1.ServiceType           `json:"type,omitempty"`
+	Ports           []ServicePortApplyConfiguration `json:"ports,omitempty"`
+	SessionAffinity *v1.ServiceAffinity             `json:"sessionAffinity,omitempty"`
+	Annotations     map[string]string               `json:"annotations,omitempty"`
+}
+
+// ServiceSpecApplyConfiguration constructs an declarative configuration of the ServiceSpec type for use with
+// apply.
+func ServiceSpec() *ServiceSpecApplyConfiguration {
+	return &ServiceSpecApplyConfiguration{}
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *ServiceSpecApplyConfiguration) WithName(value string) *ServiceSpecApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithEnabled sets the Enabled field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Enabled field is set to the value of the last call.
+func (b *ServiceSpecApplyConfiguration) WithEnabled(value bool) *ServiceSpecApplyConfiguration {
+	b.Enabled = &value
+	return b
+}
+
+// WithType sets the Type field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Type field is set to the value of the last call.
+func (b *ServiceSpecApplyConfiguration) WithType(value v1alpha1.ServiceType) *ServiceSpecApplyConfiguration {
+	b.Type = &value
+	return b
+}
+
+// WithPorts adds the given value to the Ports field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Ports field.
+func (b *ServiceSpecApplyConfiguration) WithPorts(values ...*ServicePortApplyConfiguration) *ServiceSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithPorts")
+		}
+		b.Ports = append(b.Ports, *values[i])
+	}
+	return b
+}
+
+// WithSessionAffinity sets the SessionAffinity field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SessionAffinity field is set to the value of the last call.
+func (b *ServiceSpecApplyConfiguration) WithSessionAffinity(value v1.ServiceAffinity) *ServiceSpecApplyConfiguration {
+	b.SessionAffinity = &value
+	return b
+}
+
+// WithAnnotations puts the entries into the Annotations field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the Annotations field,
+// overwriting an existing map entries in Annotations field with the same key.
+func (b *ServiceSpecApplyConfiguration) WithAnnotations(entries map[string]string) *ServiceSpecApplyConfiguration {
+	if b.Annotations == nil && len(entries) > 0 {
+		b.Annotations = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.Annotations[k] = v
+	}
+	return b
+}
@@ -0,0 +1,62 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	resource "k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
+// CustomMetricTargetApplyConfiguration represents an declarative configuration of the CustomMetricTarget type for use
+// with apply.
+type CustomMetricTargetApplyConfiguration struct {
+	Name         *string                             `json:"name,omitempty"`
+	Selector     *v1.LabelSelectorApplyConfiguration `json:"selector,omitempty"`
+	AverageValue *resource.Quantity                  `json:"averageValue,omitempty"`
+}
+
+// CustomMetricTargetApplyConfiguration constructs an declarative configuration of the CustomMetricTarget type for use with
+// apply.
+func CustomMetricTarget() *CustomMetricTargetApplyConfiguration {
+	return &CustomMetricTargetApplyConfiguration{}
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *CustomMetricTargetApplyConfiguration) WithName(value string) *CustomMetricTargetApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithSelector sets the Selector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Selector field is set to the value of the last call.
+func (b *CustomMetricTargetApplyConfiguration) WithSelector(value *v1.LabelSelectorApplyConfiguration) *CustomMetricTargetApplyConfiguration {
+	b.Selector = value
+	return b
+}
+
+// WithAverageValue sets the AverageValue field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AverageValue field is set to the value of the last call.
+func (b *CustomMetricTargetApplyConfiguration) WithAverageValue(value resource.Quantity) *CustomMetricTargetApplyConfiguration {
+	b.AverageValue = &value
+	return b
+}
@@ -0,0 +1,131 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1 "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
+// FooStatusApplyConfiguration represents an declarative configuration of the FooStatus type for use
+// with apply.
+type FooStatusApplyConfiguration struct {
+	Replicas           *int32                               `json:"replicas,omitempty"`
+	Selector           *string                              `json:"selector,omitempty"`
+	AvailableReplicas  *int32                               `json:"availableReplicas,omitempty"`
+	ReadyReplicas      *int32                               `json:"readyReplicas,omitempty"`
+	UpdatedReplicas    *int32                               `json:"updatedReplicas,omitempty"`
+	ServiceClusterIP   *string                              `json:"serviceClusterIP,omitempty"`
+	IngressAddresses   []string                             `json:"ingressAddresses,omitempty"`
+	Autoscaling        *AutoscalingStatusApplyConfiguration `json:"autoscaling,omitempty"`
+	ObservedGeneration *int64                               `json:"observedGeneration,omitempty"`
+	Conditions         []v1.ConditionApplyConfiguration     `json:"conditions,omitempty"`
+}
+
+// FooStatusApplyConfiguration constructs an declarative configuration of the FooStatus type for use with
+// apply.
+func FooStatus() *FooStatusApplyConfiguration {
+	return &FooStatusApplyConfiguration{}
+}
+
+// WithReplicas sets the Replicas field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Replicas field is set to the value of the last call.
+func (b *FooStatusApplyConfiguration) WithReplicas(value int32) *FooStatusApplyConfiguration {
+	b.Replicas = &value
+	return b
+}
+
+// WithSelector sets the Selector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Selector field is set to the value of the last call.
+func (b *FooStatusApplyConfiguration) WithSelector(value string) *FooStatusApplyConfiguration {
+	b.Selector = &value
+	return b
+}
+
+// WithAvailableReplicas sets the AvailableReplicas field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AvailableReplicas field is set to the value of the last call.
+func (b *FooStatusApplyConfiguration) WithAvailableReplicas(value int32) *FooStatusApplyConfiguration {
+	b.AvailableReplicas = &value
+	return b
+}
+
+// WithReadyReplicas sets the ReadyReplicas field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ReadyReplicas field is set to the value of the last call.
+func (b *FooStatusApplyConfiguration) WithReadyReplicas(value int32) *FooStatusApplyConfiguration {
+	b.ReadyReplicas = &value
+	return b
+}
+
+// WithUpdatedReplicas sets the UpdatedReplicas field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the UpdatedReplicas field is set to the value of the last call.
+func (b *FooStatusApplyConfiguration) WithUpdatedReplicas(value int32) *FooStatusApplyConfiguration {
+	b.UpdatedReplicas = &value
+	return b
+}
+
+// WithServiceClusterIP sets the ServiceClusterIP field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ServiceClusterIP field is set to the value of the last call.
+func (b *FooStatusApplyConfiguration) WithServiceClusterIP(value string) *FooStatusApplyConfiguration {
+	b.ServiceClusterIP = &value
+	return b
+}
+
+// WithIngressAddresses adds the given value to the IngressAddresses field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the IngressAddresses field.
+func (b *FooStatusApplyConfiguration) WithIngressAddresses(values ...string) *FooStatusApplyConfiguration {
+	for i := range values {
+		b.IngressAddresses = append(b.IngressAddresses, values[i])
+	}
+	return b
+}
+
+// WithAutoscaling sets the Autoscaling field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Autoscaling field is set to the value of the last call.
+func (b *FooStatusApplyConfiguration) WithAutoscaling(value *AutoscalingStatusApplyConfiguration) *FooStatusApplyConfiguration {
+	b.Autoscaling = value
+	return b
+}
+
+// WithObservedGeneration sets the ObservedGeneration field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ObservedGeneration field is set to the value of the last call.
+func (b *FooStatusApplyConfiguration) WithObservedGeneration(value int64) *FooStatusApplyConfiguration {
+	b.ObservedGeneration = &value
+	return b
+}
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *FooStatusApplyConfiguration) WithConditions(values ...*v1.ConditionApplyConfiguration) *FooStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithConditions")
+		}
+		b.Conditions = append(b.Conditions, *values[i])
+	}
+	return b
+}
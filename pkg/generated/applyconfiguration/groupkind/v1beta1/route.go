@@ -0,0 +1,71 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/networking/v1"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// RouteApplyConfiguration represents an declarative configuration of the Route type for use
+// with apply.
+type RouteApplyConfiguration struct {
+	Host     *string             `json:"host,omitempty"`
+	Path     *string             `json:"path,omitempty"`
+	PathType *v1.PathType        `json:"pathType,omitempty"`
+	Port     *intstr.IntOrString `json:"port,omitempty"`
+}
+
+// RouteApplyConfiguration constructs an declarative configuration of the Route type for use with
+// apply.
+func Route() *RouteApplyConfiguration {
+	return &RouteApplyConfiguration{}
+}
+
+// WithHost sets the Host field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Host field is set to the value of the last call.
+func (b *RouteApplyConfiguration) WithHost(value string) *RouteApplyConfiguration {
+	b.Host = &value
+	return b
+}
+
+// WithPath sets the Path field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Path field is set to the value of the last call.
+func (b *RouteApplyConfiguration) WithPath(value string) *RouteApplyConfiguration {
+	b.Path = &value
+	return b
+}
+
+// WithPathType sets the PathType field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PathType field is set to the value of the last call.
+func (b *RouteApplyConfiguration) WithPathType(value v1.PathType) *RouteApplyConfiguration {
+	b.PathType = &value
+	return b
+}
+
+// WithPort sets the Port field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Port field is set to the value of the last call.
+func (b *RouteApplyConfiguration) WithPort(value intstr.IntOrString) *RouteApplyConfiguration {
+	b.Port = &value
+	return b
+}
@@ -0,0 +1,90 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package applyconfiguration
+
+import (
+	v1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	v1beta1 "controller-crd/pkg/apis/groupkind/v1beta1"
+	groupkindv1alpha1 "controller-crd/pkg/generated/applyconfiguration/groupkind/v1alpha1"
+	groupkindv1beta1 "controller-crd/pkg/generated/applyconfiguration/groupkind/v1beta1"
+
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ForKind returns an apply configuration type for the given GroupVersionKind, or nil if no
+// apply configuration type exists for the given GroupVersionKind.
+func ForKind(kind schema.GroupVersionKind) interface{} {
+	switch kind {
+	// Group=groupkind.k8s.io, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithKind("AutoscalingSpec"):
+		return &groupkindv1alpha1.AutoscalingSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("AutoscalingStatus"):
+		return &groupkindv1alpha1.AutoscalingStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("CustomMetricTarget"):
+		return &groupkindv1alpha1.CustomMetricTargetApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("DeploymentSpec"):
+		return &groupkindv1alpha1.DeploymentSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("Foo"):
+		return &groupkindv1alpha1.FooApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("FooSpec"):
+		return &groupkindv1alpha1.FooSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("FooStatus"):
+		return &groupkindv1alpha1.FooStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("IngressPath"):
+		return &groupkindv1alpha1.IngressPathApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("IngressRule"):
+		return &groupkindv1alpha1.IngressRuleApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("IngressSpec"):
+		return &groupkindv1alpha1.IngressSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ServicePort"):
+		return &groupkindv1alpha1.ServicePortApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ServiceSpec"):
+		return &groupkindv1alpha1.ServiceSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("TeardownSpec"):
+		return &groupkindv1alpha1.TeardownSpecApplyConfiguration{}
+
+		// Group=groupkind.k8s.io, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithKind("AutoscalingSpec"):
+		return &groupkindv1beta1.AutoscalingSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("AutoscalingStatus"):
+		return &groupkindv1beta1.AutoscalingStatusApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("CustomMetricTarget"):
+		return &groupkindv1beta1.CustomMetricTargetApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("Foo"):
+		return &groupkindv1beta1.FooApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("FooSpec"):
+		return &groupkindv1beta1.FooSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("FooStatus"):
+		return &groupkindv1beta1.FooStatusApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("IngressSpec"):
+		return &groupkindv1beta1.IngressSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("Route"):
+		return &groupkindv1beta1.RouteApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("ServicePort"):
+		return &groupkindv1beta1.ServicePortApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("ServiceSpec"):
+		return &groupkindv1beta1.ServiceSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("TeardownSpec"):
+		return &groupkindv1beta1.TeardownSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("WorkloadSpec"):
+		return &groupkindv1beta1.WorkloadSpecApplyConfiguration{}
+
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ package versioned
 
 import (
 	groupkindv1alpha1 "controller-crd/pkg/generated/clientset/versioned/typed/groupkind/v1alpha1"
+	groupkindv1beta1 "controller-crd/pkg/generated/clientset/versioned/typed/groupkind/v1beta1"
 	"fmt"
 	"net/http"
 
@@ -31,12 +32,14 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	GroupkindV1alpha1() groupkindv1alpha1.GroupkindV1alpha1Interface
+	GroupkindV1beta1() groupkindv1beta1.GroupkindV1beta1Interface
 }
 
 // Clientset contains the clients for groups.
 type Clientset struct {
 	*discovery.DiscoveryClient
 	groupkindV1alpha1 *groupkindv1alpha1.GroupkindV1alpha1Client
+	groupkindV1beta1  *groupkindv1beta1.GroupkindV1beta1Client
 }
 
 // GroupkindV1alpha1 retrieves the GroupkindV1alpha1Client
@@ -44,6 +47,11 @@ func (c *Clientset) GroupkindV1alpha1() groupkindv1alpha1.GroupkindV1alpha1Inter
 	return c.groupkindV1alpha1
 }
 
+// GroupkindV1beta1 retrieves the GroupkindV1beta1Client
+func (c *Clientset) GroupkindV1beta1() groupkindv1beta1.GroupkindV1beta1Interface {
+	return c.groupkindV1beta1
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -92,6 +100,10 @@ func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset,
 	if err != nil {
 		return nil, err
 	}
+	cs.groupkindV1beta1, err = groupkindv1beta1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
@@ -114,6 +126,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.groupkindV1alpha1 = groupkindv1alpha1.New(c)
+	cs.groupkindV1beta1 = groupkindv1beta1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
@@ -22,6 +22,8 @@ import (
 	clientset "controller-crd/pkg/generated/clientset/versioned"
 	groupkindv1alpha1 "controller-crd/pkg/generated/clientset/versioned/typed/groupkind/v1alpha1"
 	fakegroupkindv1alpha1 "controller-crd/pkg/generated/clientset/versioned/typed/groupkind/v1alpha1/fake"
+	groupkindv1beta1 "controller-crd/pkg/generated/clientset/versioned/typed/groupkind/v1beta1"
+	fakegroupkindv1beta1 "controller-crd/pkg/generated/clientset/versioned/typed/groupkind/v1beta1/fake"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
@@ -84,3 +86,8 @@ var (
 func (c *Clientset) GroupkindV1alpha1() groupkindv1alpha1.GroupkindV1alpha1Interface {
 	return &fakegroupkindv1alpha1.FakeGroupkindV1alpha1{Fake: &c.Fake}
 }
+
+// GroupkindV1beta1 retrieves the GroupkindV1beta1Client
+func (c *Clientset) GroupkindV1beta1() groupkindv1beta1.GroupkindV1beta1Interface {
+	return &fakegroupkindv1beta1.FakeGroupkindV1beta1{Fake: &c.Fake}
+}
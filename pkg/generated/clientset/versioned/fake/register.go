@@ -20,6 +20,7 @@ package fake
 
 import (
 	groupkindv1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	groupkindv1beta1 "controller-crd/pkg/apis/groupkind/v1beta1"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
@@ -33,6 +34,7 @@ var codecs = serializer.NewCodecFactory(scheme)
 
 var localSchemeBuilder = runtime.SchemeBuilder{
 	groupkindv1alpha1.AddToScheme,
+	groupkindv1beta1.AddToScheme,
 }
 
 // AddToScheme adds all types of this clientset into the given scheme. This allows composition
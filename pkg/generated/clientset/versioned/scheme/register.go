@@ -20,6 +20,7 @@ package scheme
 
 import (
 	groupkindv1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	groupkindv1beta1 "controller-crd/pkg/apis/groupkind/v1beta1"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
@@ -33,6 +34,7 @@ var Codecs = serializer.NewCodecFactory(Scheme)
 var ParameterCodec = runtime.NewParameterCodec(Scheme)
 var localSchemeBuilder = runtime.SchemeBuilder{
 	groupkindv1alpha1.AddToScheme,
+	groupkindv1beta1.AddToScheme,
 }
 
 // AddToScheme adds all types of this clientset into the given scheme. This allows composition
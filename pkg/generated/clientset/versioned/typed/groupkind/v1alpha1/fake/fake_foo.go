@@ -21,6 +21,9 @@ package fake
 import (
 	"context"
 	v1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	groupkindv1alpha1 "controller-crd/pkg/generated/applyconfiguration/groupkind/v1alpha1"
+	json "encoding/json"
+	"fmt"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
@@ -139,3 +142,48 @@ func (c *FakeFoos) Patch(ctx context.Context, name string, pt types.PatchType, d
 	}
 	return obj.(*v1alpha1.Foo), err
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied foo.
+func (c *FakeFoos) Apply(ctx context.Context, foo *groupkindv1alpha1.FooApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.Foo, err error) {
+	if foo == nil {
+		return nil, fmt.Errorf("foo provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(foo)
+	if err != nil {
+		return nil, err
+	}
+	name := foo.Name
+	if name == nil {
+		return nil, fmt.Errorf("foo.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(foosResource, c.ns, *name, types.ApplyPatchType, data), &v1alpha1.Foo{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.Foo), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeFoos) ApplyStatus(ctx context.Context, foo *groupkindv1alpha1.FooApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.Foo, err error) {
+	if foo == nil {
+		return nil, fmt.Errorf("foo provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(foo)
+	if err != nil {
+		return nil, err
+	}
+	name := foo.Name
+	if name == nil {
+		return nil, fmt.Errorf("foo.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(foosResource, c.ns, *name, types.ApplyPatchType, data, "status"), &v1alpha1.Foo{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.Foo), err
+}
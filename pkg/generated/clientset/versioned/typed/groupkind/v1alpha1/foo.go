@@ -21,7 +21,10 @@ package v1alpha1
 import (
 	"context"
 	v1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	groupkindv1alpha1 "controller-crd/pkg/generated/applyconfiguration/groupkind/v1alpha1"
 	scheme "controller-crd/pkg/generated/clientset/versioned/scheme"
+	json "encoding/json"
+	"fmt"
 	"time"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -47,6 +50,8 @@ type FooInterface interface {
 	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.FooList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
 	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.Foo, err error)
+	Apply(ctx context.Context, foo *groupkindv1alpha1.FooApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.Foo, err error)
+	ApplyStatus(ctx context.Context, foo *groupkindv1alpha1.FooApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.Foo, err error)
 	FooExpansion
 }
 
@@ -193,3 +198,59 @@ func (c *foos) Patch(ctx context.Context, name string, pt types.PatchType, data
 		Into(result)
 	return
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied foo.
+func (c *foos) Apply(ctx context.Context, foo *groupkindv1alpha1.FooApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.Foo, err error) {
+	if foo == nil {
+		return nil, fmt.Errorf("foo provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(foo)
+	if err != nil {
+		return nil, err
+	}
+	name := foo.Name
+	if name == nil {
+		return nil, fmt.Errorf("foo.Name must be provided to Apply")
+	}
+	result = &v1alpha1.Foo{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("foos").
+		Name(*name).
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *foos) ApplyStatus(ctx context.Context, foo *groupkindv1alpha1.FooApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.Foo, err error) {
+	if foo == nil {
+		return nil, fmt.Errorf("foo provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(foo)
+	if err != nil {
+		return nil, err
+	}
+
+	name := foo.Name
+	if name == nil {
+		return nil, fmt.Errorf("foo.Name must be provided to Apply")
+	}
+
+	result = &v1alpha1.Foo{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("foos").
+		Name(*name).
+		SubResource("status").
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
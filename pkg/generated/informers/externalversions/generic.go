@@ -20,6 +20,7 @@ package externalversions
 
 import (
 	v1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	v1beta1 "controller-crd/pkg/apis/groupkind/v1beta1"
 	"fmt"
 
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -56,6 +57,10 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case v1alpha1.SchemeGroupVersion.WithResource("foos"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Groupkind().V1alpha1().Foos().Informer()}, nil
 
+		// Group=groupkind.k8s.io, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithResource("foos"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Groupkind().V1beta1().Foos().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// managedAnnotationsAnnotation records, on the children rendering
+// annotations set in the App spec, the keys of those annotations, so that
+// the ones removed from the spec can be removed from the children while the
+// annotations of other actors are kept.
+const managedAnnotationsAnnotation = "groupkind.k8s.io/managed-annotations"
+
+// reconcileDeployment merges the fields rendered by newDeployment into a copy
+// of the actual Deployment. It returns the merged copy and whether it differs
+// from actual, in which case the copy should be sent as an Update.
+func reconcileDeployment(desired, actual *appsv1.Deployment) (*appsv1.Deployment, bool) {
+	updated := actual.DeepCopy()
+	updated.Labels = mergeStringMap(updated.Labels, desired.Labels)
+	updated.Annotations = mergeStringMap(updated.Annotations, desired.Annotations)
+	// No replicas are rendered while a HorizontalPodAutoscaler scales the
+	// Deployment, and the ones it set are kept.
+	if desired.Spec.Replicas != nil {
+		updated.Spec.Replicas = desired.Spec.Replicas
+	}
+	updated.Spec.Template.Labels = mergeStringMap(updated.Spec.Template.Labels, desired.Spec.Template.Labels)
+	updated.Spec.Template.Spec.ImagePullSecrets = desired.Spec.Template.Spec.ImagePullSecrets
+	updated.Spec.Template.Spec.Containers = reconcileContainers(desired.Spec.Template.Spec.Containers, updated.Spec.Template.Spec.Containers)
+
+	changed := !equality.Semantic.DeepEqual(actual.ObjectMeta, updated.ObjectMeta) ||
+		!equality.Semantic.DeepEqual(actual.Spec, updated.Spec)
+	return updated, changed
+}
+
+// reconcileContainers returns the desired containers, each one carrying over
+// the server-defaulted fields of the actual container with the same name.
+// Containers that are not rendered by the controller are dropped.
+func reconcileContainers(desired, actual []corev1.Container) []corev1.Container {
+	existing := make(map[string]corev1.Container, len(actual))
+	for _, c := range actual {
+		existing[c.Name] = c
+	}
+
+	containers := make([]corev1.Container, 0, len(desired))
+	for _, want := range desired {
+		c, ok := existing[want.Name]
+		if !ok {
+			containers = append(containers, want)
+			continue
+		}
+		c.Image = want.Image
+		c.Command = want.Command
+		c.Args = want.Args
+		c.Env = want.Env
+		c.Ports = want.Ports
+		c.Resources = want.Resources
+		c.LivenessProbe = want.LivenessProbe
+		c.ReadinessProbe = want.ReadinessProbe
+		c.StartupProbe = want.StartupProbe
+		if want.ImagePullPolicy != "" {
+			c.ImagePullPolicy = want.ImagePullPolicy
+		}
+		containers = append(containers, c)
+	}
+	return containers
+}
+
+// defaultEnv fills in the API version of field references like the API
+// server does.
+func defaultEnv(env []corev1.EnvVar) []corev1.EnvVar {
+	if env == nil {
+		return nil
+	}
+	defaulted := make([]corev1.EnvVar, 0, len(env))
+	for _, e := range env {
+		e = *e.DeepCopy()
+		if e.ValueFrom != nil && e.ValueFrom.FieldRef != nil && e.ValueFrom.FieldRef.APIVersion == "" {
+			e.ValueFrom.FieldRef.APIVersion = "v1"
+		}
+		defaulted = append(defaulted, e)
+	}
+	return defaulted
+}
+
+// defaultContainerPorts fills in the TCP protocol like the API server does.
+func defaultContainerPorts(ports []corev1.ContainerPort) []corev1.ContainerPort {
+	if ports == nil {
+		return nil
+	}
+	defaulted := make([]corev1.ContainerPort, 0, len(ports))
+	for _, p := range ports {
+		if p.Protocol == "" {
+			p.Protocol = corev1.ProtocolTCP
+		}
+		defaulted = append(defaulted, p)
+	}
+	return defaulted
+}
+
+// defaultResources copies limits into missing requests like the API server
+// does.
+func defaultResources(resources corev1.ResourceRequirements) corev1.ResourceRequirements {
+	defaulted := *resources.DeepCopy()
+	for name, limit := range defaulted.Limits {
+		if _, ok := defaulted.Requests[name]; ok {
+			continue
+		}
+		if defaulted.Requests == nil {
+			defaulted.Requests = corev1.ResourceList{}
+		}
+		defaulted.Requests[name] = limit.DeepCopy()
+	}
+	return defaulted
+}
+
+// defaultProbe fills in the timings and the HTTP path and scheme of a probe
+// like the API server does.
+func defaultProbe(probe *corev1.Probe) *corev1.Probe {
+	if probe == nil {
+		return nil
+	}
+	defaulted := probe.DeepCopy()
+	if defaulted.TimeoutSeconds == 0 {
+		defaulted.TimeoutSeconds = 1
+	}
+	if defaulted.PeriodSeconds == 0 {
+		defaulted.PeriodSeconds = 10
+	}
+	if defaulted.SuccessThreshold == 0 {
+		defaulted.SuccessThreshold = 1
+	}
+	if defaulted.FailureThreshold == 0 {
+		defaulted.FailureThreshold = 3
+	}
+	if defaulted.HTTPGet != nil {
+		if defaulted.HTTPGet.Path == "" {
+			defaulted.HTTPGet.Path = "/"
+		}
+		if defaulted.HTTPGet.Scheme == "" {
+			defaulted.HTTPGet.Scheme = corev1.URISchemeHTTP
+		}
+	}
+	return defaulted
+}
+
+// reconcileHorizontalPodAutoscaler merges the fields rendered by
+// newHorizontalPodAutoscaler into a copy of the actual HorizontalPodAutoscaler.
+// The scaling behavior isn't rendered, so one set by hand is kept.
+func reconcileHorizontalPodAutoscaler(desired, actual *autoscalingv2.HorizontalPodAutoscaler) (*autoscalingv2.HorizontalPodAutoscaler, bool) {
+	updated := actual.DeepCopy()
+	updated.Labels = mergeStringMap(updated.Labels, desired.Labels)
+	updated.Annotations = mergeStringMap(updated.Annotations, desired.Annotations)
+	updated.Spec.ScaleTargetRef = desired.Spec.ScaleTargetRef
+	updated.Spec.MinReplicas = desired.Spec.MinReplicas
+	updated.Spec.MaxReplicas = desired.Spec.MaxReplicas
+	updated.Spec.Metrics = desired.Spec.Metrics
+
+	changed := !equality.Semantic.DeepEqual(actual.ObjectMeta, updated.ObjectMeta) ||
+		!equality.Semantic.DeepEqual(actual.Spec, updated.Spec)
+	return updated, changed
+}
+
+// reconcileService merges the fields rendered by newService into a copy of
+// the actual Service. Immutable or allocated fields such as the cluster IP are
+// left untouched.
+func reconcileService(desired, actual *corev1.Service) (*corev1.Service, bool) {
+	updated := actual.DeepCopy()
+	updated.Labels = mergeStringMap(updated.Labels, desired.Labels)
+	updated.Annotations = reconcileAnnotations(updated.Annotations, desired.Annotations)
+	updated.Spec.Selector = desired.Spec.Selector
+	updated.Spec.Type = desired.Spec.Type
+	updated.Spec.SessionAffinity = desired.Spec.SessionAffinity
+	if desired.Spec.SessionAffinity == corev1.ServiceAffinityNone {
+		updated.Spec.SessionAffinityConfig = nil
+	}
+	updated.Spec.Ports = reconcileServicePorts(desired.Spec.Ports, actual.Spec.Ports, desired.Spec.Type)
+
+	changed := !equality.Semantic.DeepEqual(actual.ObjectMeta, updated.ObjectMeta) ||
+		!equality.Semantic.DeepEqual(actual.Spec, updated.Spec)
+	return updated, changed
+}
+
+// reconcileServicePorts returns the desired ports, keeping the node port the
+// API server allocated for a port of the same number when none was requested
+// and the Service type still uses node ports.
+func reconcileServicePorts(desired, actual []corev1.ServicePort, serviceType corev1.ServiceType) []corev1.ServicePort {
+	allocated := make(map[int32]int32, len(actual))
+	for _, p := range actual {
+		allocated[p.Port] = p.NodePort
+	}
+	usesNodePorts := serviceType == corev1.ServiceTypeNodePort || serviceType == corev1.ServiceTypeLoadBalancer
+
+	ports := make([]corev1.ServicePort, 0, len(desired))
+	for _, p := range desired {
+		if p.NodePort == 0 && usesNodePorts {
+			p.NodePort = allocated[p.Port]
+		}
+		ports = append(ports, p)
+	}
+	return ports
+}
+
+// serviceNeedsRecreate reports whether the desired and actual Service differ
+// in being headless, which can't be changed in place.
+func serviceNeedsRecreate(desired, actual *corev1.Service) bool {
+	return (desired.Spec.ClusterIP == corev1.ClusterIPNone) != (actual.Spec.ClusterIP == corev1.ClusterIPNone)
+}
+
+// reconcileIngress merges the fields rendered by newIngress into a copy of the
+// actual Ingress. The controller owns the whole rule set and the TLS sections;
+// the ingress class is only overwritten when the App sets one, so a class
+// defaulted at admission is kept.
+func reconcileIngress(desired, actual *v1.Ingress) (*v1.Ingress, bool) {
+	updated := actual.DeepCopy()
+	updated.Labels = mergeStringMap(updated.Labels, desired.Labels)
+	updated.Annotations = reconcileAnnotations(updated.Annotations, desired.Annotations)
+	updated.Spec.Rules = desired.Spec.Rules
+	updated.Spec.TLS = desired.Spec.TLS
+	if desired.Spec.IngressClassName != nil {
+		updated.Spec.IngressClassName = desired.Spec.IngressClassName
+	}
+
+	changed := !equality.Semantic.DeepEqual(actual.ObjectMeta, updated.ObjectMeta) ||
+		!equality.Semantic.DeepEqual(actual.Spec, updated.Spec)
+	return updated, changed
+}
+
+// mergeStringMap sets every key of desired on a copy of actual, so that keys
+// added by other actors survive.
+func mergeStringMap(actual, desired map[string]string) map[string]string {
+	if len(desired) == 0 {
+		return actual
+	}
+	merged := make(map[string]string, len(actual)+len(desired))
+	for k, v := range actual {
+		merged[k] = v
+	}
+	for k, v := range desired {
+		merged[k] = v
+	}
+	return merged
+}
+
+// withManagedAnnotations returns a copy of the annotations of the App spec
+// recording their keys under managedAnnotationsAnnotation, or nil when there
+// are none.
+func withManagedAnnotations(annotations map[string]string) map[string]string {
+	if len(annotations) == 0 {
+		return nil
+	}
+	managed := make(map[string]string, len(annotations)+1)
+	keys := make([]string, 0, len(annotations))
+	for k, v := range annotations {
+		managed[k] = v
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	managed[managedAnnotationsAnnotation] = strings.Join(keys, ",")
+	return managed
+}
+
+// reconcileAnnotations merges the desired annotations, as rendered by
+// withManagedAnnotations, into a copy of the actual ones, after removing the
+// keys recorded under managedAnnotationsAnnotation that are no longer
+// desired. Keys added by other actors survive.
+func reconcileAnnotations(actual, desired map[string]string) map[string]string {
+	recorded, ok := actual[managedAnnotationsAnnotation]
+	if !ok {
+		return mergeStringMap(actual, desired)
+	}
+	reconciled := make(map[string]string, len(actual)+len(desired))
+	for k, v := range actual {
+		reconciled[k] = v
+	}
+	delete(reconciled, managedAnnotationsAnnotation)
+	for _, k := range strings.Split(recorded, ",") {
+		if _, ok := desired[k]; !ok {
+			delete(reconciled, k)
+		}
+	}
+	for k, v := range desired {
+		reconciled[k] = v
+	}
+	return reconciled
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	groupkindv1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ReasonDeploymentUnavailable is used as the condition reason when the
+	// Deployment doesn't have its minimum number of available pods.
+	ReasonDeploymentUnavailable = "DeploymentUnavailable"
+	// ReasonRolloutInProgress is used as the condition reason while the
+	// Deployment is rolling out a new pod template.
+	ReasonRolloutInProgress = "RolloutInProgress"
+	// ReasonRenameInProgress is used as the condition reason while the
+	// Service or Ingress of a App is replaced by one with a new name.
+	ReasonRenameInProgress = "RenameInProgress"
+	// ReasonScalingPending is used as the condition reason until the
+	// HorizontalPodAutoscaler of a App reports whether it can scale.
+	ReasonScalingPending = "ScalingPending"
+)
+
+// setSyncedConditions sets the conditions of a App whose children were all
+// reconciled successfully. service and ingress are nil when disabled.
+func setSyncedConditions(status *groupkindv1alpha1.FooStatus, generation int64, deployment *appsv1.Deployment, service *corev1.Service, ingress *v1.Ingress) {
+	status.ObservedGeneration = generation
+
+	available := deploymentAvailable(deployment)
+	if available {
+		setCondition(status, generation, groupkindv1alpha1.FooDeploymentAvailable, metav1.ConditionTrue, SuccessSynced,
+			fmt.Sprintf("Deployment %q has %d available replicas", deployment.Name, deployment.Status.AvailableReplicas))
+	} else {
+		setCondition(status, generation, groupkindv1alpha1.FooDeploymentAvailable, metav1.ConditionFalse, ReasonDeploymentUnavailable,
+			fmt.Sprintf("Deployment %q has %d of %d replicas available", deployment.Name, deployment.Status.AvailableReplicas, desiredReplicas(deployment)))
+	}
+
+	if deploymentProgressing(deployment) {
+		setCondition(status, generation, groupkindv1alpha1.FooProgressing, metav1.ConditionTrue, ReasonRolloutInProgress,
+			fmt.Sprintf("Deployment %q has %d of %d replicas updated", deployment.Name, deployment.Status.UpdatedReplicas, desiredReplicas(deployment)))
+	} else {
+		setCondition(status, generation, groupkindv1alpha1.FooProgressing, metav1.ConditionFalse, SuccessSynced,
+			fmt.Sprintf("Deployment %q is rolled out", deployment.Name))
+	}
+
+	// Optional children that are disabled have no condition at all.
+	if service != nil {
+		setCondition(status, generation, groupkindv1alpha1.FooServiceReady, metav1.ConditionTrue, SuccessSynced,
+			fmt.Sprintf("Service %q is synced", service.Name))
+	} else {
+		meta.RemoveStatusCondition(&status.Conditions, groupkindv1alpha1.FooServiceReady)
+	}
+	if ingress != nil {
+		setCondition(status, generation, groupkindv1alpha1.FooIngressReady, metav1.ConditionTrue, SuccessSynced,
+			fmt.Sprintf("Ingress %q is synced", ingress.Name))
+	} else {
+		meta.RemoveStatusCondition(&status.Conditions, groupkindv1alpha1.FooIngressReady)
+	}
+	setCondition(status, generation, groupkindv1alpha1.FooDegraded, metav1.ConditionFalse, SuccessSynced, MessageResourceSynced)
+
+	if available {
+		setCondition(status, generation, groupkindv1alpha1.FooReady, metav1.ConditionTrue, SuccessSynced, MessageResourceSynced)
+	} else {
+		setCondition(status, generation, groupkindv1alpha1.FooReady, metav1.ConditionFalse, ReasonDeploymentUnavailable,
+			fmt.Sprintf("Deployment %q is not available", deployment.Name))
+	}
+}
+
+// setRenameConditions marks a App as progressing while the children it owns
+// under an old name are being deleted. renames describes each of them.
+func setRenameConditions(status *groupkindv1alpha1.FooStatus, generation int64, renames []string) {
+	if len(renames) == 0 {
+		return
+	}
+	setCondition(status, generation, groupkindv1alpha1.FooProgressing, metav1.ConditionTrue, ReasonRenameInProgress,
+		strings.Join(renames, "; "))
+}
+
+// setAutoscalingStatus surfaces the state of the HorizontalPodAutoscaler of a
+// App, and whether it is able to scale, in its status. hpa is nil when
+// autoscaling is disabled.
+func setAutoscalingStatus(status *groupkindv1alpha1.FooStatus, generation int64, hpa *autoscalingv2.HorizontalPodAutoscaler) {
+	if hpa == nil {
+		status.Autoscaling = nil
+		meta.RemoveStatusCondition(&status.Conditions, groupkindv1alpha1.FooScalingActive)
+		return
+	}
+
+	status.Autoscaling = &groupkindv1alpha1.AutoscalingStatus{
+		CurrentReplicas: hpa.Status.CurrentReplicas,
+		DesiredReplicas: hpa.Status.DesiredReplicas,
+		LastScaleTime:   hpa.Status.LastScaleTime,
+	}
+	for _, m := range hpa.Status.CurrentMetrics {
+		if m.Type != autoscalingv2.ResourceMetricSourceType || m.Resource == nil {
+			continue
+		}
+		switch m.Resource.Name {
+		case corev1.ResourceCPU:
+			status.Autoscaling.CurrentCPUUtilizationPercentage = m.Resource.Current.AverageUtilization
+		case corev1.ResourceMemory:
+			status.Autoscaling.CurrentMemoryUtilizationPercentage = m.Resource.Current.AverageUtilization
+		}
+	}
+
+	// The condition mirrors the ScalingActive condition of the autoscaler,
+	// which the autoscaler sets once it has read its metrics.
+	for _, c := range hpa.Status.Conditions {
+		if c.Type != autoscalingv2.ScalingActive || c.Reason == "" {
+			continue
+		}
+		setCondition(status, generation, groupkindv1alpha1.FooScalingActive, metav1.ConditionStatus(c.Status), c.Reason, c.Message)
+		return
+	}
+	setCondition(status, generation, groupkindv1alpha1.FooScalingActive, metav1.ConditionUnknown, ReasonScalingPending,
+		fmt.Sprintf("HorizontalPodAutoscaler %q has not read its metrics yet", hpa.Name))
+}
+
+// setFailedConditions marks a App whose sync failed with the given reason
+// as degraded and not ready.
+func setFailedConditions(status *groupkindv1alpha1.FooStatus, generation int64, reason, message string) {
+	status.ObservedGeneration = generation
+	setCondition(status, generation, groupkindv1alpha1.FooDegraded, metav1.ConditionTrue, reason, message)
+	setCondition(status, generation, groupkindv1alpha1.FooReady, metav1.ConditionFalse, reason, message)
+}
+
+func setCondition(status *groupkindv1alpha1.FooStatus, generation int64, conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// deploymentAvailable prefers the Available condition maintained by the
+// deployment controller and falls back to comparing replica counts.
+func deploymentAvailable(deployment *appsv1.Deployment) bool {
+	for _, c := range deployment.Status.Conditions {
+		if c.Type == appsv1.DeploymentAvailable {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return deployment.Status.AvailableReplicas >= desiredReplicas(deployment)
+}
+
+func deploymentProgressing(deployment *appsv1.Deployment) bool {
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return true
+	}
+	return deployment.Status.UpdatedReplicas < desiredReplicas(deployment) ||
+		deployment.Status.Replicas > deployment.Status.UpdatedReplicas
+}
+
+func desiredReplicas(deployment *appsv1.Deployment) int32 {
+	if deployment.Spec.Replicas == nil {
+		return 1
+	}
+	return *deployment.Spec.Replicas
+}
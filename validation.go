@@ -0,0 +1,310 @@
+package main
+
+import (
+	groupkindv1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	groupkindlister "controller-crd/pkg/generated/listers/groupkind/v1alpha1"
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	networkingv1listers "k8s.io/client-go/listers/networking/v1"
+)
+
+// imageReferenceRegexp matches the image references accepted for a App: an
+// optional registry host and port, lowercase path components, and an
+// optional tag and digest. It is the pattern on DeploymentSpec.Image.
+var imageReferenceRegexp = regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*(:[0-9]+)?(/[a-z0-9]+([._-][a-z0-9]+)*)*(:[A-Za-z0-9_][A-Za-z0-9_.-]{0,127})?(@sha256:[a-f0-9]{64})?$`)
+
+// validateFoo checks the spec of a App on its own.
+func validateFoo(foo *groupkindv1alpha1.Foo) field.ErrorList {
+	specPath := field.NewPath("spec")
+	allErrs := validateDeploymentSpec(&foo.Spec.Deployment, specPath.Child("deployment"))
+	if foo.Spec.Service != nil {
+		allErrs = append(allErrs, validateServiceSpec(foo.Spec.Service, specPath.Child("service"))...)
+	}
+	if foo.Spec.Ingress != nil {
+		ingressPath := specPath.Child("ingress")
+		if foo.Spec.Ingress.Enabled == nil || *foo.Spec.Ingress.Enabled {
+			if !serviceEnabled(foo) {
+				allErrs = append(allErrs, field.Forbidden(ingressPath, "an ingress requires an enabled service"))
+			}
+		}
+		allErrs = append(allErrs, validateIngressSpec(foo.Spec.Ingress, ingressPath)...)
+	}
+	if foo.Spec.Teardown != nil && foo.Spec.Teardown.TimeoutSeconds != nil && *foo.Spec.Teardown.TimeoutSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("teardown", "timeoutSeconds"), *foo.Spec.Teardown.TimeoutSeconds, "must not be negative"))
+	}
+	if foo.Spec.Autoscaling != nil {
+		allErrs = append(allErrs, validateAutoscalingSpec(foo.Spec.Autoscaling, specPath.Child("autoscaling"))...)
+	}
+	return allErrs
+}
+
+func validateDeploymentSpec(spec *groupkindv1alpha1.DeploymentSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), ""))
+	} else {
+		// The name is used for both the Deployment and its container.
+		for _, msg := range validation.IsDNS1123Label(spec.Name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), spec.Name, msg))
+		}
+	}
+	if spec.Image == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("image"), ""))
+	} else if !imageReferenceRegexp.MatchString(spec.Image) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("image"), spec.Image, "must be a valid image reference, e.g. registry.example.com/team/app:v1"))
+	}
+	if spec.Replicas != nil && *spec.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), *spec.Replicas, "must not be negative"))
+	}
+	return allErrs
+}
+
+func validateServiceSpec(spec *groupkindv1alpha1.ServiceSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec.Name != "" {
+		for _, msg := range validation.IsDNS1035Label(spec.Name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), spec.Name, msg))
+		}
+	}
+	switch spec.Type {
+	case "", groupkindv1alpha1.ServiceTypeClusterIP, groupkindv1alpha1.ServiceTypeNodePort,
+		groupkindv1alpha1.ServiceTypeLoadBalancer, groupkindv1alpha1.ServiceTypeHeadless:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), spec.Type, []string{
+			string(groupkindv1alpha1.ServiceTypeClusterIP), string(groupkindv1alpha1.ServiceTypeNodePort),
+			string(groupkindv1alpha1.ServiceTypeLoadBalancer), string(groupkindv1alpha1.ServiceTypeHeadless),
+		}))
+	}
+	switch spec.SessionAffinity {
+	case "", corev1.ServiceAffinityNone, corev1.ServiceAffinityClientIP:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("sessionAffinity"), spec.SessionAffinity, []string{
+			string(corev1.ServiceAffinityNone), string(corev1.ServiceAffinityClientIP),
+		}))
+	}
+
+	names := sets.NewString()
+	for i, port := range spec.Ports {
+		portPath := fldPath.Child("ports").Index(i)
+		if port.Name == "" && len(spec.Ports) > 1 {
+			allErrs = append(allErrs, field.Required(portPath.Child("name"), "required when the service has more than one port"))
+		} else if port.Name != "" {
+			for _, msg := range validation.IsDNS1123Label(port.Name) {
+				allErrs = append(allErrs, field.Invalid(portPath.Child("name"), port.Name, msg))
+			}
+			if names.Has(port.Name) {
+				allErrs = append(allErrs, field.Duplicate(portPath.Child("name"), port.Name))
+			}
+			names.Insert(port.Name)
+		}
+		for _, msg := range validation.IsValidPortNum(int(port.Port)) {
+			allErrs = append(allErrs, field.Invalid(portPath.Child("port"), port.Port, msg))
+		}
+		if port.NodePort != 0 {
+			for _, msg := range validation.IsValidPortNum(int(port.NodePort)) {
+				allErrs = append(allErrs, field.Invalid(portPath.Child("nodePort"), port.NodePort, msg))
+			}
+		}
+		allErrs = append(allErrs, validatePortReference(port.TargetPort, portPath.Child("targetPort"))...)
+	}
+	return allErrs
+}
+
+func validateIngressSpec(spec *groupkindv1alpha1.IngressSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec.Name != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(spec.Name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), spec.Name, msg))
+		}
+	}
+
+	routes := map[ingressRoute]bool{}
+	for i, rule := range spec.Rules {
+		rulePath := fldPath.Child("rules").Index(i)
+		if rule.Host != "" {
+			host := strings.TrimPrefix(rule.Host, "*.")
+			for _, msg := range validation.IsDNS1123Subdomain(host) {
+				allErrs = append(allErrs, field.Invalid(rulePath.Child("host"), rule.Host, msg))
+			}
+		}
+		if len(rule.Paths) == 0 {
+			allErrs = append(allErrs, field.Required(rulePath.Child("paths"), ""))
+		}
+		for j, p := range rule.Paths {
+			pathPath := rulePath.Child("paths").Index(j)
+			if p.Path != "" && !strings.HasPrefix(p.Path, "/") {
+				allErrs = append(allErrs, field.Invalid(pathPath.Child("path"), p.Path, "must be an absolute path"))
+			}
+			if p.PathType != nil {
+				switch *p.PathType {
+				case v1.PathTypeExact, v1.PathTypePrefix, v1.PathTypeImplementationSpecific:
+				default:
+					allErrs = append(allErrs, field.NotSupported(pathPath.Child("pathType"), *p.PathType, []string{
+						string(v1.PathTypeExact), string(v1.PathTypePrefix), string(v1.PathTypeImplementationSpecific),
+					}))
+				}
+			}
+			allErrs = append(allErrs, validatePortReference(p.Port, pathPath.Child("port"))...)
+
+			route := ingressRoute{host: rule.Host, path: ingressPath(p)}
+			if routes[route] {
+				allErrs = append(allErrs, field.Duplicate(pathPath.Child("path"), route.path))
+			}
+			routes[route] = true
+		}
+	}
+	return allErrs
+}
+
+func validateAutoscalingSpec(spec *groupkindv1alpha1.AutoscalingSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec.MaxReplicas < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxReplicas"), spec.MaxReplicas, "must be at least 1"))
+	}
+	if spec.MinReplicas != nil {
+		if *spec.MinReplicas < 1 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("minReplicas"), *spec.MinReplicas, "must be at least 1"))
+		} else if *spec.MinReplicas > spec.MaxReplicas {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("maxReplicas"), spec.MaxReplicas, "must be greater than or equal to minReplicas"))
+		}
+	}
+	if p := spec.TargetCPUUtilizationPercentage; p != nil && *p < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("targetCPUUtilizationPercentage"), *p, "must be at least 1"))
+	}
+	if p := spec.TargetMemoryUtilizationPercentage; p != nil && *p < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("targetMemoryUtilizationPercentage"), *p, "must be at least 1"))
+	}
+
+	names := sets.NewString()
+	for i, m := range spec.CustomMetrics {
+		metricPath := fldPath.Child("customMetrics").Index(i)
+		if m.Name == "" {
+			allErrs = append(allErrs, field.Required(metricPath.Child("name"), ""))
+		} else if names.Has(m.Name) {
+			allErrs = append(allErrs, field.Duplicate(metricPath.Child("name"), m.Name))
+		}
+		names.Insert(m.Name)
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(m.Selector, metav1validation.LabelSelectorValidationOptions{}, metricPath.Child("selector"))...)
+		if m.AverageValue.Sign() <= 0 {
+			allErrs = append(allErrs, field.Invalid(metricPath.Child("averageValue"), m.AverageValue.String(), "must be positive"))
+		}
+	}
+	return allErrs
+}
+
+// validatePortReference checks a port given by number or name. The zero
+// value means the default port.
+func validatePortReference(port intstr.IntOrString, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch {
+	case port.Type == intstr.String && port.StrVal != "":
+		for _, msg := range validation.IsValidPortName(port.StrVal) {
+			allErrs = append(allErrs, field.Invalid(fldPath, port.StrVal, msg))
+		}
+	case port.Type == intstr.Int && port.IntVal != 0:
+		for _, msg := range validation.IsValidPortNum(int(port.IntVal)) {
+			allErrs = append(allErrs, field.Invalid(fldPath, port.IntVal, msg))
+		}
+	}
+	return allErrs
+}
+
+// validateIngressRoutes checks that no other App of the namespace, and no
+// Ingress of the namespace not managed by one of those Apps, already routes
+// one of the hosts and paths of foo. On update, old is the App before the
+// update, and the routes it already had are not checked again, so that Apps
+// that conflicted before the webhook was installed can still be updated.
+func validateIngressRoutes(foo, old *groupkindv1alpha1.Foo, foosLister groupkindlister.FooLister, ingressesLister networkingv1listers.IngressLister) (field.ErrorList, error) {
+	if !ingressEnabled(foo) {
+		return nil, nil
+	}
+	existing := map[ingressRoute]bool{}
+	if old != nil {
+		for _, r := range ingressRoutes(old) {
+			existing[r] = true
+		}
+	}
+	others, err := foosLister.Foos(foo.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	conflicts := map[ingressRoute]*ingressConflict{}
+	for _, other := range others {
+		if other.Name == foo.Name || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		for _, r := range ingressRoutes(other) {
+			conflicts[r] = &ingressConflict{route: r, owner: other.Name}
+		}
+	}
+	ingresses, err := ingressesLister.Ingresses(foo.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, ingress := range unmanagedIngresses(ingresses, append(others, foo)) {
+		for _, r := range ingressObjectRoutes(ingress) {
+			if _, ok := conflicts[r]; !ok {
+				conflicts[r] = &ingressConflict{route: r, owner: ingress.Name, ingress: true}
+			}
+		}
+	}
+
+	var allErrs field.ErrorList
+	rulesPath := field.NewPath("spec", "ingress", "rules")
+	for i, rule := range foo.Spec.Ingress.Rules {
+		if rule.Host == "" {
+			continue
+		}
+		for j, p := range rule.Paths {
+			route := ingressRoute{host: rule.Host, path: ingressPath(p)}
+			if existing[route] {
+				continue
+			}
+			if conflict, ok := conflicts[route]; ok {
+				allErrs = append(allErrs, field.Forbidden(rulesPath.Index(i).Child("paths").Index(j), conflict.String()))
+			}
+		}
+	}
+	return allErrs, nil
+}
+
+// validateServiceName checks that the Service of foo isn't a Service of the
+// namespace controlled by something other than foo. On create foo has no UID
+// yet, so the controller reference is matched by kind and name instead.
+func validateServiceName(foo *groupkindv1alpha1.Foo, servicesLister corev1listers.ServiceLister) (field.ErrorList, error) {
+	if !serviceEnabled(foo) {
+		return nil, nil
+	}
+	name := serviceName(foo)
+	service, err := servicesLister.Services(foo.Namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ref := metav1.GetControllerOf(service); ref != nil {
+		if foo.UID != "" && ref.UID == foo.UID {
+			return nil, nil
+		}
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if foo.UID == "" && err == nil && gv.Group == fooGVK.Group && ref.Kind == fooGVK.Kind && ref.Name == foo.Name {
+			return nil, nil
+		}
+	}
+	return field.ErrorList{field.Forbidden(field.NewPath("spec", "service", "name"), fmt.Sprintf(MessageResourceExists, name))}, nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	groupkindv1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	groupkindlister "controller-crd/pkg/generated/listers/groupkind/v1alpha1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	networkingv1listers "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// mutatingWebhookPath is the path the defaulting webhook of Apps is
+	// served on.
+	mutatingWebhookPath = "/mutate-groupkind-k8s-io-v1alpha1-foo"
+	// validatingWebhookPath is the path the validating webhook of Apps is
+	// served on.
+	validatingWebhookPath = "/validate-groupkind-k8s-io-v1alpha1-foo"
+
+	// maxAdmissionReviewBytes bounds the size of an AdmissionReview read
+	// from the API server.
+	maxAdmissionReviewBytes = 3 * 1024 * 1024
+)
+
+// webhookServer serves the admission and conversion webhooks of Apps.
+type webhookServer struct {
+	foosLister      groupkindlister.FooLister
+	servicesLister  corev1listers.ServiceLister
+	ingressesLister networkingv1listers.IngressLister
+	cachesSynced    []cache.InformerSynced
+}
+
+// webhookInformers returns the informers the webhooks read from, and the
+// factories the caller must start for them along with the App informers. The
+// webhooks share the App informers of the controller but get Service and
+// Ingress informers of their own: the ones of the controller are only
+// started by the replica holding the lease, and the webhooks are served by
+// every replica. They are not restricted by the label selector either, as a
+// Service or Ingress collides with an App whatever its labels.
+func webhookInformers(kubeClient kubernetes.Interface, informers []Informers, resyncPeriod time.Duration) ([]Informers, []kubeinformers.SharedInformerFactory) {
+	var factories []kubeinformers.SharedInformerFactory
+	webhookInformers := make([]Informers, 0, len(informers))
+	for _, i := range informers {
+		factory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, resyncPeriod, kubeinformers.WithNamespace(i.Namespace))
+		factories = append(factories, factory)
+		webhookInformers = append(webhookInformers, Informers{
+			Namespace: i.Namespace,
+			Foos:      i.Foos,
+			Services:  factory.Core().V1().Services(),
+			Ingresses: factory.Networking().V1().Ingresses(),
+		})
+	}
+	return webhookInformers, factories
+}
+
+// newWebhookServer returns a webhook server reading Apps, Services and
+// Ingresses from the informers of informers, as returned by
+// webhookInformers. The informers must be started by the caller.
+func newWebhookServer(informers []Informers) *webhookServer {
+	foosLister := namespacedFooLister{}
+	servicesLister := namespacedServiceLister{}
+	ingressesLister := namespacedIngressLister{}
+	var cachesSynced []cache.InformerSynced
+	for _, i := range informers {
+		foosLister[i.Namespace] = i.Foos.Lister()
+		servicesLister[i.Namespace] = i.Services.Lister()
+		ingressesLister[i.Namespace] = i.Ingresses.Lister()
+		cachesSynced = append(cachesSynced, i.Foos.Informer().HasSynced, i.Services.Informer().HasSynced, i.Ingresses.Informer().HasSynced)
+	}
+	return &webhookServer{foosLister: foosLister, servicesLister: servicesLister, ingressesLister: ingressesLister, cachesSynced: cachesSynced}
+}
+
+// install registers the webhooks on mux.
+func (s *webhookServer) install(mux *http.ServeMux) {
+	mux.Handle(mutatingWebhookPath, admissionHandler(s.mutate))
+	mux.Handle(validatingWebhookPath, admissionHandler(s.validate))
+	mux.Handle(conversionWebhookPath, conversionHandler())
+}
+
+// hasSynced reports whether the informers of the webhooks have synced.
+func (s *webhookServer) hasSynced() bool {
+	for _, synced := range s.cachesSynced {
+		if !synced() {
+			return false
+		}
+	}
+	return true
+}
+
+// mutate fills in the defaults of the Apps. The controller applies the same
+// defaults to the Apps it syncs, so the webhook only makes them visible.
+func (s *webhookServer) mutate(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return allowed()
+	}
+	foo := &groupkindv1alpha1.Foo{}
+	if err := json.Unmarshal(req.Object.Raw, foo); err != nil {
+		return denied(errors.NewBadRequest(fmt.Sprintf("failed to decode app: %s", err.Error())))
+	}
+	defaulted := withDefaults(foo)
+
+	// The spec and the labels are replaced as a whole rather than diffed
+	// field by field: unknown fields are pruned by the CRD schema anyway.
+	var patch []jsonPatchOperation
+	if !equality.Semantic.DeepEqual(foo.Spec, defaulted.Spec) {
+		patch = append(patch, jsonPatchOperation{Op: "replace", Path: "/spec", Value: defaulted.Spec})
+	}
+	if !equality.Semantic.DeepEqual(foo.Labels, defaulted.Labels) {
+		patch = append(patch, jsonPatchOperation{Op: "add", Path: "/metadata/labels", Value: defaulted.Labels})
+	}
+	if len(patch) == 0 {
+		return allowed()
+	}
+	raw, err := json.Marshal(patch)
+	if err != nil {
+		return denied(errors.NewInternalError(err))
+	}
+	patchType := admissionv1.PatchTypeJSONPatch
+	return &admissionv1.AdmissionResponse{Allowed: true, Patch: raw, PatchType: &patchType}
+}
+
+// jsonPatchOperation is an operation of a JSON patch (RFC 6902).
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// validate admits the Apps whose spec is valid, whose ingress routes no host
+// and path already routed by another App, and whose Service doesn't name a
+// Service managed by something else.
+func (s *webhookServer) validate(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return allowed()
+	}
+	foo := &groupkindv1alpha1.Foo{}
+	if err := json.Unmarshal(req.Object.Raw, foo); err != nil {
+		return denied(errors.NewBadRequest(fmt.Sprintf("failed to decode app: %s", err.Error())))
+	}
+	// The finalizer of a App being deleted must always be removable, even if
+	// the App predates the rules it breaks.
+	if !foo.DeletionTimestamp.IsZero() {
+		return allowed()
+	}
+	var old *groupkindv1alpha1.Foo
+	if req.Operation == admissionv1.Update {
+		old = &groupkindv1alpha1.Foo{}
+		if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+			return denied(errors.NewBadRequest(fmt.Sprintf("failed to decode old app: %s", err.Error())))
+		}
+	}
+
+	// The spec is checked with its defaults, as the controller renders it,
+	// whether or not the defaulting webhook ran first.
+	defaulted := withDefaults(foo)
+	allErrs := validateFoo(defaulted)
+	if !s.hasSynced() {
+		return denied(errors.NewServiceUnavailable("app, service and ingress informer caches not synced"))
+	}
+	routeErrs, err := validateIngressRoutes(foo, old, s.foosLister, s.ingressesLister)
+	if err != nil {
+		return denied(errors.NewInternalError(err))
+	}
+	allErrs = append(allErrs, routeErrs...)
+	serviceErrs, err := validateServiceName(defaulted, s.servicesLister)
+	if err != nil {
+		return denied(errors.NewInternalError(err))
+	}
+	allErrs = append(allErrs, serviceErrs...)
+
+	if len(allErrs) > 0 {
+		klog.V(4).Infof("Rejecting app '%s/%s': %s", req.Namespace, foo.Name, allErrs.ToAggregate().Error())
+		return denied(errors.NewInvalid(groupkindv1alpha1.Kind("Foo"), foo.Name, allErrs))
+	}
+	return allowed()
+}
+
+// admitFunc reviews an admission request.
+type admitFunc func(*admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse
+
+// admissionHandler decodes the AdmissionReview posted by the API server,
+// passes its request to admit and writes the response back.
+func admissionHandler(admit admitFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+			http.Error(w, fmt.Sprintf("unsupported content type %q", contentType), http.StatusUnsupportedMediaType)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxAdmissionReviewBytes))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		review := admissionv1.AdmissionReview{}
+		if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+			http.Error(w, "failed to decode admission review", http.StatusBadRequest)
+			return
+		}
+
+		response := admit(review.Request)
+		response.UID = review.Request.UID
+		review.Request = nil
+		review.Response = response
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(review); err != nil {
+			klog.Errorf("Error writing admission response: %s", err.Error())
+		}
+	})
+}
+
+func allowed() *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+func denied(err *errors.StatusError) *admissionv1.AdmissionResponse {
+	status := err.Status()
+	return &admissionv1.AdmissionResponse{Allowed: false, Result: &status}
+}
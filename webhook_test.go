@@ -0,0 +1,519 @@
+package main
+
+import (
+	"bytes"
+	groupkindv1alpha1 "controller-crd/pkg/apis/groupkind/v1alpha1"
+	groupkindv1beta1 "controller-crd/pkg/apis/groupkind/v1beta1"
+	"controller-crd/pkg/generated/clientset/versioned/fake"
+	informers "controller-crd/pkg/generated/informers/externalversions"
+	groupkindlister "controller-crd/pkg/generated/listers/groupkind/v1alpha1"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubeinformers "k8s.io/client-go/informers"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	networkingv1listers "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// withRoute makes foo route host and path to its Service.
+func withRoute(foo *groupkindv1alpha1.Foo, host, path string) *groupkindv1alpha1.Foo {
+	foo = withServiceAndIngress(foo)
+	foo.Spec.Ingress.Rules = []groupkindv1alpha1.IngressRule{{
+		Host:  host,
+		Paths: []groupkindv1alpha1.IngressPath{{Path: path}},
+	}}
+	groupkindv1alpha1.SetObjectDefaults_Foo(foo)
+	return foo
+}
+
+func TestValidateFoo(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*groupkindv1alpha1.Foo)
+		fields []string
+	}{
+		{name: "valid", mutate: func(foo *groupkindv1alpha1.Foo) {}},
+		{
+			name:   "valid with service and ingress",
+			mutate: func(foo *groupkindv1alpha1.Foo) { withRoute(foo, "example.com", "/api") },
+		},
+		{
+			name:   "missing image",
+			mutate: func(foo *groupkindv1alpha1.Foo) { foo.Spec.Deployment.Image = "" },
+			fields: []string{"spec.deployment.image"},
+		},
+		{
+			name:   "invalid image",
+			mutate: func(foo *groupkindv1alpha1.Foo) { foo.Spec.Deployment.Image = "Nginx:latest" },
+			fields: []string{"spec.deployment.image"},
+		},
+		{
+			name: "image with registry and digest",
+			mutate: func(foo *groupkindv1alpha1.Foo) {
+				foo.Spec.Deployment.Image = "registry.example.com:5000/team/app@sha256:" + strings.Repeat("a", 64)
+			},
+		},
+		{
+			name:   "invalid deployment name",
+			mutate: func(foo *groupkindv1alpha1.Foo) { foo.Spec.Deployment.Name = "Test_Deployment" },
+			fields: []string{"spec.deployment.name"},
+		},
+		{
+			name:   "negative replicas",
+			mutate: func(foo *groupkindv1alpha1.Foo) { *foo.Spec.Deployment.Replicas = -1 },
+			fields: []string{"spec.deployment.replicas"},
+		},
+		{
+			name: "invalid service name and type",
+			mutate: func(foo *groupkindv1alpha1.Foo) {
+				foo.Spec.Service = &groupkindv1alpha1.ServiceSpec{Name: "1-service", Type: "External"}
+			},
+			fields: []string{"spec.service.name", "spec.service.type"},
+		},
+		{
+			name: "unnamed and duplicate ports",
+			mutate: func(foo *groupkindv1alpha1.Foo) {
+				foo.Spec.Service = &groupkindv1alpha1.ServiceSpec{Ports: []groupkindv1alpha1.ServicePort{
+					{Port: 80},
+					{Name: "http", Port: 8080},
+					{Name: "http", Port: 70000},
+				}}
+			},
+			fields: []string{"spec.service.ports[0].name", "spec.service.ports[2].name", "spec.service.ports[2].port"},
+		},
+		{
+			name: "ingress without service",
+			mutate: func(foo *groupkindv1alpha1.Foo) {
+				foo.Spec.Ingress = &groupkindv1alpha1.IngressSpec{}
+			},
+			fields: []string{"spec.ingress"},
+		},
+		{
+			name: "invalid ingress rules",
+			mutate: func(foo *groupkindv1alpha1.Foo) {
+				withRoute(foo, "Example.com", "api")
+				foo.Spec.Ingress.Rules = append(foo.Spec.Ingress.Rules,
+					groupkindv1alpha1.IngressRule{Host: "*.example.com"},
+					groupkindv1alpha1.IngressRule{Host: "example.org", Paths: []groupkindv1alpha1.IngressPath{{}, {Path: "/"}}})
+			},
+			fields: []string{
+				"spec.ingress.rules[0].host", "spec.ingress.rules[0].paths[0].path",
+				"spec.ingress.rules[1].paths", "spec.ingress.rules[2].paths[1].path",
+			},
+		},
+		{
+			name: "negative teardown timeout",
+			mutate: func(foo *groupkindv1alpha1.Foo) {
+				timeout := int32(-1)
+				foo.Spec.Teardown = &groupkindv1alpha1.TeardownSpec{TimeoutSeconds: &timeout}
+			},
+			fields: []string{"spec.teardown.timeoutSeconds"},
+		},
+		{
+			name:   "valid autoscaling",
+			mutate: func(foo *groupkindv1alpha1.Foo) { withAutoscaling(foo, 2, 10) },
+		},
+		{
+			name: "invalid autoscaling",
+			mutate: func(foo *groupkindv1alpha1.Foo) {
+				withAutoscaling(foo, 3, 2)
+				memory := int32(0)
+				foo.Spec.Autoscaling.TargetMemoryUtilizationPercentage = &memory
+				foo.Spec.Autoscaling.CustomMetrics = []groupkindv1alpha1.CustomMetricTarget{
+					{Name: "requests_per_second", AverageValue: resource.MustParse("100")},
+					{Name: "requests_per_second", AverageValue: resource.MustParse("-1")},
+				}
+			},
+			fields: []string{
+				"spec.autoscaling.maxReplicas", "spec.autoscaling.targetMemoryUtilizationPercentage",
+				"spec.autoscaling.customMetrics[1].name", "spec.autoscaling.customMetrics[1].averageValue",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			foo := newFoo("test", 1)
+			tt.mutate(foo)
+			var fields []string
+			for _, err := range validateFoo(foo) {
+				fields = append(fields, err.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("expected errors on %v, got %v", tt.fields, validateFoo(foo))
+			}
+		})
+	}
+}
+
+func newFooLister(foos ...*groupkindv1alpha1.Foo) groupkindlister.FooLister {
+	indexer := emptyIndexer()
+	for _, foo := range foos {
+		indexer.Add(foo)
+	}
+	return groupkindlister.NewFooLister(indexer)
+}
+
+func newIngressLister(ingresses ...*v1.Ingress) networkingv1listers.IngressLister {
+	indexer := emptyIndexer()
+	for _, ingress := range ingresses {
+		indexer.Add(ingress)
+	}
+	return networkingv1listers.NewIngressLister(indexer)
+}
+
+func TestValidateIngressRoutes(t *testing.T) {
+	other := withRoute(newFoo("other", 1), "example.com", "/api")
+	deleting := withRoute(newFoo("deleting", 1), "example.com", "/old")
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+	lister := newFooLister(other, deleting)
+	// The Ingresses of listed Apps are checked through their App, and the
+	// one of the App being deleted is free along with it.
+	ingressLister := newIngressLister(newIngress(other), newIngress(deleting))
+
+	foo := withRoute(newFoo("test", 1), "example.com", "/api")
+	errs, err := validateIngressRoutes(foo, nil, lister, ingressLister)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Field != "spec.ingress.rules[0].paths[0]" {
+		t.Errorf("expected a conflict on the route of %s, got %v", other.Name, errs)
+	}
+
+	// A route already held before the update is not checked again.
+	if errs, _ := validateIngressRoutes(foo, foo.DeepCopy(), lister, ingressLister); len(errs) != 0 {
+		t.Errorf("expected no errors on update, got %v", errs)
+	}
+	// Routes of Apps being deleted are free.
+	foo = withRoute(newFoo("test", 1), "example.com", "/old")
+	if errs, _ := validateIngressRoutes(foo, nil, lister, ingressLister); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+
+	// Ingresses not managed by a listed App keep their routes, whether they
+	// have no owner or belong to an App outside the label selector.
+	unowned := newIngress(withRoute(newFoo("legacy", 1), "example.com", "/legacy"))
+	unowned.OwnerReferences = nil
+	unlisted := newIngress(withRoute(newFoo("unlisted", 1), "example.com", "/unlisted"))
+	ingressLister = newIngressLister(unowned, unlisted)
+	for _, path := range []string{"/legacy", "/unlisted"} {
+		foo = withRoute(newFoo("test", 1), "example.com", path)
+		errs, err := validateIngressRoutes(foo, nil, lister, ingressLister)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != 1 || !strings.Contains(errs[0].Detail, "Ingress") {
+			t.Errorf("expected a conflict with an Ingress on %s, got %v", path, errs)
+		}
+	}
+}
+
+func newServiceLister(services ...*corev1.Service) corev1listers.ServiceLister {
+	indexer := emptyIndexer()
+	for _, service := range services {
+		indexer.Add(service)
+	}
+	return corev1listers.NewServiceLister(indexer)
+}
+
+func TestValidateServiceName(t *testing.T) {
+	foo := withServiceAndIngress(newFoo("test", 1))
+	other := withServiceAndIngress(newFoo("other", 1))
+
+	owned := newService(foo)
+	if errs, err := validateServiceName(foo, newServiceLister(owned)); err != nil || len(errs) != 0 {
+		t.Errorf("expected the App's own Service to be accepted, got %v, %v", errs, err)
+	}
+	// On create the App has no UID yet; a Service left behind by an App of
+	// the same name is its to adopt.
+	created := foo.DeepCopy()
+	created.UID = ""
+	if errs, _ := validateServiceName(created, newServiceLister(owned)); len(errs) != 0 {
+		t.Errorf("expected the Service of an App of the same name to be accepted on create, got %v", errs)
+	}
+
+	claimed := newService(foo)
+	claimed.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(other, fooGVK)}
+	unowned := newService(foo)
+	unowned.OwnerReferences = nil
+	for _, service := range []*corev1.Service{claimed, unowned} {
+		errs, err := validateServiceName(foo, newServiceLister(service))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != 1 || errs[0].Field != "spec.service.name" {
+			t.Errorf("expected a collision on spec.service.name with %v, got %v", service.OwnerReferences, errs)
+		}
+		if errs, _ := validateServiceName(created, newServiceLister(service)); len(errs) != 1 {
+			t.Errorf("expected a collision on create with %v, got %v", service.OwnerReferences, errs)
+		}
+	}
+
+	disabled := foo.DeepCopy()
+	disabled.Spec.Ingress = nil
+	disabled.Spec.Service.Enabled = new(bool)
+	if errs, _ := validateServiceName(disabled, newServiceLister(unowned)); len(errs) != 0 {
+		t.Errorf("expected no collision without a Service, got %v", errs)
+	}
+}
+
+func reviewFoo(t *testing.T, server *webhookServer, path string, operation admissionv1.Operation, foo *groupkindv1alpha1.Foo) *admissionv1.AdmissionResponse {
+	t.Helper()
+	raw, err := json.Marshal(foo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: admissionv1.SchemeGroupVersion.String(), Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       types.UID("review-uid"),
+			Operation: operation,
+			Namespace: foo.Namespace,
+			Name:      foo.Name,
+			Object:    runtime.RawExtension{Raw: raw},
+			OldObject: runtime.RawExtension{Raw: raw},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	server.install(mux)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	review := admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil {
+		t.Fatal(err)
+	}
+	if review.Response == nil || review.Response.UID != "review-uid" {
+		t.Fatalf("expected a response to review-uid, got %#v", review.Response)
+	}
+	return review.Response
+}
+
+func TestWebhookValidate(t *testing.T) {
+	other := withRoute(newFoo("other", 1), "example.com", "/")
+	unowned := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "taken", Namespace: metav1.NamespaceDefault}}
+	synced := true
+	server := &webhookServer{
+		foosLister:      newFooLister(other),
+		servicesLister:  newServiceLister(unowned),
+		ingressesLister: newIngressLister(),
+		cachesSynced:    []cache.InformerSynced{func() bool { return synced }},
+	}
+
+	if resp := reviewFoo(t, server, validatingWebhookPath, admissionv1.Create, newFoo("test", 1)); !resp.Allowed {
+		t.Errorf("expected a valid App to be allowed, got %v", resp.Result)
+	}
+
+	invalid := newFoo("test", -1)
+	invalid.Spec.Deployment.Image = ""
+	resp := reviewFoo(t, server, validatingWebhookPath, admissionv1.Create, invalid)
+	if resp.Allowed || resp.Result.Reason != metav1.StatusReasonInvalid || len(resp.Result.Details.Causes) != 2 {
+		t.Errorf("expected an invalid App to be denied with two causes, got %v", resp.Result)
+	}
+
+	conflicting := withRoute(newFoo("test", 1), "example.com", "/")
+	if resp := reviewFoo(t, server, validatingWebhookPath, admissionv1.Create, conflicting); resp.Allowed {
+		t.Errorf("expected a conflicting route to be denied")
+	}
+
+	colliding := withServiceAndIngress(newFoo("test", 1))
+	colliding.Spec.Service.Name = "taken"
+	resp = reviewFoo(t, server, validatingWebhookPath, admissionv1.Create, colliding)
+	if resp.Allowed || len(resp.Result.Details.Causes) != 1 || resp.Result.Details.Causes[0].Field != "spec.service.name" {
+		t.Errorf("expected a Service name taken by another Service to be denied, got %v", resp.Result)
+	}
+
+	// Apps being deleted are let through so that their finalizer can be removed.
+	now := metav1.Now()
+	invalid.DeletionTimestamp = &now
+	if resp := reviewFoo(t, server, validatingWebhookPath, admissionv1.Update, invalid); !resp.Allowed {
+		t.Errorf("expected an App being deleted to be allowed, got %v", resp.Result)
+	}
+
+	synced = false
+	if resp := reviewFoo(t, server, validatingWebhookPath, admissionv1.Create, newFoo("test", 1)); resp.Allowed || resp.Result.Reason != metav1.StatusReasonServiceUnavailable {
+		t.Errorf("expected the review to fail until the caches have synced, got %v", resp.Result)
+	}
+}
+
+func TestWebhookInformersSyncWithoutTheLease(t *testing.T) {
+	unowned := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "taken", Namespace: metav1.NamespaceDefault}}
+	client := fake.NewSimpleClientset()
+	kubeClient := k8sfake.NewSimpleClientset(unowned)
+	groupKindInformerFactory := informers.NewSharedInformerFactory(client, noResyncPeriodFunc())
+	// The kube informers of the controller are only started by the replica
+	// holding the lease, so they are never started here.
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, noResyncPeriodFunc())
+	controllerInformers := []Informers{{
+		Namespace: metav1.NamespaceAll,
+		Services:  kubeInformerFactory.Core().V1().Services(),
+		Foos:      groupKindInformerFactory.Groupkind().V1alpha1().Foos(),
+	}}
+
+	webhookInformers, webhookInformerFactories := webhookInformers(kubeClient, controllerInformers, noResyncPeriodFunc())
+	server := newWebhookServer(webhookInformers)
+	if server.hasSynced() {
+		t.Fatal("expected the caches not to be synced before the informers are started")
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	groupKindInformerFactory.Start(stopCh)
+	for _, factory := range webhookInformerFactories {
+		factory.Start(stopCh)
+	}
+	if !cache.WaitForCacheSync(stopCh, server.hasSynced) {
+		t.Fatal("expected the caches to sync")
+	}
+
+	colliding := withServiceAndIngress(newFoo("test", 1))
+	colliding.Spec.Service.Name = "taken"
+	if resp := reviewFoo(t, server, validatingWebhookPath, admissionv1.Create, colliding); resp.Allowed {
+		t.Errorf("expected a Service name taken by another Service to be denied")
+	}
+}
+
+func TestWebhookMutate(t *testing.T) {
+	server := &webhookServer{foosLister: newFooLister()}
+
+	if resp := reviewFoo(t, server, mutatingWebhookPath, admissionv1.Create, newFoo("test", 1)); !resp.Allowed || resp.Patch != nil {
+		t.Errorf("expected an App with its defaults to be allowed unchanged, got patch %s", resp.Patch)
+	}
+
+	foo := &groupkindv1alpha1.Foo{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+		Spec: groupkindv1alpha1.FooSpec{
+			Deployment: groupkindv1alpha1.DeploymentSpec{Image: "nginx:1.23"},
+			Service:    &groupkindv1alpha1.ServiceSpec{},
+		},
+	}
+	resp := reviewFoo(t, server, mutatingWebhookPath, admissionv1.Create, foo)
+	if !resp.Allowed || resp.PatchType == nil || *resp.PatchType != admissionv1.PatchTypeJSONPatch {
+		t.Fatalf("expected an allowed JSON patch, got %#v", resp)
+	}
+	var patch []struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(resp.Patch, &patch); err != nil {
+		t.Fatal(err)
+	}
+	if len(patch) != 2 || patch[0].Path != "/spec" || patch[1].Path != "/metadata/labels" {
+		t.Fatalf("expected the spec and the labels to be patched, got %s", resp.Patch)
+	}
+	spec := groupkindv1alpha1.FooSpec{}
+	if err := json.Unmarshal(patch[0].Value, &spec); err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(spec, withDefaults(foo).Spec) {
+		t.Errorf("expected the spec to be patched to its defaults, got %s", patch[0].Value)
+	}
+	if spec.Deployment.Name != "test" || *spec.Deployment.Replicas != 1 || spec.Service.Name != "test" || spec.Service.Ports[0].Port != 80 {
+		t.Errorf("expected names after the app, 1 replica and port 80, got %s", patch[0].Value)
+	}
+}
+
+func TestAdmissionHandlerRejectsBadRequests(t *testing.T) {
+	handler := admissionHandler(func(*admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse { return allowed() })
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, validatingWebhookPath, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405 for GET, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, validatingWebhookPath, strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a review without request, got %d", rec.Code)
+	}
+}
+
+func TestConversionWebhook(t *testing.T) {
+	foo := withRoute(newFoo("test", 2), "example.com", "/api")
+	foo.Kind = "Foo"
+	raw, err := json.Marshal(foo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	convert := func(desiredAPIVersion string, objects ...runtime.RawExtension) *apiextensionsv1.ConversionResponse {
+		body, err := json.Marshal(apiextensionsv1.ConversionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: apiextensionsv1.SchemeGroupVersion.String(), Kind: "ConversionReview"},
+			Request: &apiextensionsv1.ConversionRequest{
+				UID:               types.UID("review-uid"),
+				DesiredAPIVersion: desiredAPIVersion,
+				Objects:           objects,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(http.MethodPost, conversionWebhookPath, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		conversionHandler().ServeHTTP(rec, req)
+		review := apiextensionsv1.ConversionReview{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil {
+			t.Fatalf("failed to decode response %q: %s", rec.Body.String(), err.Error())
+		}
+		if review.Response == nil || review.Response.UID != "review-uid" {
+			t.Fatalf("expected a response to review-uid, got %#v", review.Response)
+		}
+		return review.Response
+	}
+
+	resp := convert(groupkindv1beta1.SchemeGroupVersion.String(), runtime.RawExtension{Raw: raw})
+	if resp.Result.Status != metav1.StatusSuccess || len(resp.ConvertedObjects) != 1 {
+		t.Fatalf("expected one converted app, got %#v", resp)
+	}
+	spoke := &groupkindv1beta1.Foo{}
+	if err := json.Unmarshal(resp.ConvertedObjects[0].Raw, spoke); err != nil {
+		t.Fatal(err)
+	}
+	if spoke.APIVersion != groupkindv1beta1.SchemeGroupVersion.String() || spoke.Spec.Workload.Name != foo.Spec.Deployment.Name ||
+		len(spoke.Spec.Ingress.Routes) != 1 || spoke.Spec.Ingress.Routes[0].Path != "/api" {
+		t.Errorf("expected the app converted to %s, got %s", groupkindv1beta1.SchemeGroupVersion, resp.ConvertedObjects[0].Raw)
+	}
+
+	resp = convert(groupkindv1alpha1.SchemeGroupVersion.String(), resp.ConvertedObjects[0])
+	if resp.Result.Status != metav1.StatusSuccess || len(resp.ConvertedObjects) != 1 {
+		t.Fatalf("expected one converted app, got %#v", resp)
+	}
+	hub := &groupkindv1alpha1.Foo{}
+	if err := json.Unmarshal(resp.ConvertedObjects[0].Raw, hub); err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(hub, foo) {
+		t.Errorf("expected the app back unchanged, got %s", resp.ConvertedObjects[0].Raw)
+	}
+
+	if resp := convert("groupkind.k8s.io/v2", runtime.RawExtension{Raw: raw}); resp.Result.Status != metav1.StatusFailure {
+		t.Errorf("expected the conversion to an unknown version to fail, got %#v", resp.Result)
+	}
+}